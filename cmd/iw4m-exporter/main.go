@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m"
+	"github.com/Yallamaztar/iw4m-go/iw4m/exporter"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+func main() {
+	baseURL := flag.String("url", "http://127.0.0.1:1624", "IW4M-Admin webfront URL")
+	serverID := flag.String("server-id", "", "default server id")
+	listen := flag.String("listen", ":9908", "address to serve metrics on")
+	interval := flag.Duration("interval", 30*time.Second, "time between webfront scrapes")
+	flag.Parse()
+
+	// The cookie is read from the environment so it stays out of process listings
+	cookie := os.Getenv("IW4M_COOKIE")
+
+	wrapper := iw4m.NewWrapper(*baseURL, *serverID, cookie)
+	exp := exporter.NewExporter(server.NewServer(wrapper), *interval)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go exp.Run(ctx)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", exp.Handler())
+
+	srv := &http.Server{Addr: *listen, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		srv.Shutdown(context.Background())
+	}()
+
+	log.Printf("serving metrics on %s/metrics", *listen)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+}
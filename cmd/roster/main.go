@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m"
+	"github.com/Yallamaztar/iw4m-go/iw4m/roster"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+const usage = `usage: roster <command> [flags]
+
+commands:
+  plan   show the level changes needed to match the roster
+  apply  apply the level changes and record them in the audit trail
+
+The webfront cookie is read from the IW4M_COOKIE environment variable.`
+
+func main() {
+	log.SetFlags(0)
+	if err := run(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Run a command and return its error, so deferred cleanup such as closing
+// the audit trail happens before main exits
+func run(args []string) error {
+	if len(args) < 1 {
+		return errors.New(usage)
+	}
+
+	flags := flag.NewFlagSet(args[0], flag.ExitOnError)
+	baseURL := flags.String("url", "http://127.0.0.1:1624", "IW4M-Admin webfront URL")
+	serverID := flags.String("server-id", "", "default server id")
+	rosterPath := flags.String("roster", "roster.yaml", "roster file (YAML or JSON)")
+
+	switch args[0] {
+	case "plan":
+		out := flags.String("out", "", "save the plan as JSON for a later apply")
+		flags.Parse(args[1:])
+
+		srv := connect(*baseURL, *serverID)
+		plan, err := makePlan(*rosterPath, srv)
+		if err != nil {
+			return err
+		}
+		plan.Print(os.Stdout)
+
+		if *out != "" {
+			data, err := json.MarshalIndent(plan, "", "  ")
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(*out, data, 0o644); err != nil {
+				return err
+			}
+			fmt.Printf("\nSaved plan to %s\n", *out)
+		}
+		return nil
+
+	case "apply":
+		planPath := flags.String("plan", "", "apply a plan saved by 'roster plan -out'")
+		auditPath := flags.String("audit", "roster-audit.jsonl", "audit trail file")
+		dryRun := flags.Bool("dry-run", false, "print the plan without applying it")
+		flags.Parse(args[1:])
+
+		srv := connect(*baseURL, *serverID)
+
+		var plan *roster.Plan
+		if *planPath != "" {
+			data, err := os.ReadFile(*planPath)
+			if err != nil {
+				return err
+			}
+			plan = &roster.Plan{}
+			if err := json.Unmarshal(data, plan); err != nil {
+				return fmt.Errorf("failed to parse plan: %w", err)
+			}
+		} else {
+			var err error
+			if plan, err = makePlan(*rosterPath, srv); err != nil {
+				return err
+			}
+		}
+
+		plan.Print(os.Stdout)
+		if *dryRun || len(plan.Steps) == 0 {
+			return nil
+		}
+
+		audit, err := os.OpenFile(*auditPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		defer audit.Close()
+
+		if err := plan.Apply(srv, audit); err != nil {
+			if errors.Is(err, roster.ErrStale) {
+				return fmt.Errorf("levels changed since the plan was made, run 'roster plan' again:\n%w", err)
+			}
+			return fmt.Errorf("apply failed: %w", err)
+		}
+		fmt.Printf("\nApplied %d changes, audit trail in %s\n", len(plan.Steps), *auditPath)
+		return nil
+	}
+
+	return errors.New(usage)
+}
+
+func connect(baseURL, serverID string) *server.Server {
+	return server.NewServer(iw4m.NewWrapper(baseURL, serverID, os.Getenv("IW4M_COOKIE")))
+}
+
+func makePlan(path string, srv *server.Server) (*roster.Plan, error) {
+	r, err := roster.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := r.Plan(srv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan: %w", err)
+	}
+	return plan, nil
+}
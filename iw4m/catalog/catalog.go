@@ -0,0 +1,124 @@
+package catalog
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type Map struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	// DLC is the map pack the map ships in, empty for base game maps
+	DLC     string   `json:"dlc,omitempty"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+type GameType struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+type Game struct {
+	Code      string     `json:"code"`
+	Name      string     `json:"name"`
+	Maps      []Map      `json:"maps"`
+	GameTypes []GameType `json:"gameTypes"`
+}
+
+// Look up a game by its ServerStatus.Game code, e.g. "IW4"
+func Lookup(game string) (*Game, bool) {
+	g, ok := games[strings.ToUpper(strings.TrimSpace(game))]
+	return g, ok
+}
+
+// Return the codes of every game in the catalog
+func Games() []string {
+	codes := make([]string, 0, len(games))
+	for code := range games {
+		codes = append(codes, code)
+	}
+	slices.Sort(codes)
+	return codes
+}
+
+// Find a map by engine code, display name or alias, ignoring case, spaces
+// and punctuation
+func (g *Game) Map(name string) (Map, bool) {
+	key := normalize(name)
+	for _, m := range g.Maps {
+		if normalize(m.Code) == key || normalize(m.Name) == key {
+			return m, true
+		}
+		for _, alias := range m.Aliases {
+			if normalize(alias) == key {
+				return m, true
+			}
+		}
+	}
+	return Map{}, false
+}
+
+// Find a gametype by code or display name
+func (g *Game) GameType(name string) (GameType, bool) {
+	key := normalize(name)
+	for _, gt := range g.GameTypes {
+		if normalize(gt.Code) == key || normalize(gt.Name) == key {
+			return gt, true
+		}
+	}
+	return GameType{}, false
+}
+
+// Return the display name of a map, or the code itself when unknown
+func MapName(game, code string) string {
+	if g, ok := Lookup(game); ok {
+		if m, ok := g.Map(code); ok {
+			return m.Name
+		}
+	}
+	return code
+}
+
+// Return the display name of a gametype, or the code itself when unknown
+func GameTypeName(game, code string) string {
+	if g, ok := Lookup(game); ok {
+		if gt, ok := g.GameType(code); ok {
+			return gt.Name
+		}
+	}
+	return code
+}
+
+// Check that a map exists for a game and, when mode is not empty, that the
+// gametype exists too. The catalog only lists multiplayer maps, which can be
+// played in every gametype of their game. The resolved map is returned
+func ValidateMap(game, name, mode string) (Map, error) {
+	g, ok := Lookup(game)
+	if !ok {
+		return Map{}, fmt.Errorf("unknown game %q", game)
+	}
+
+	m, ok := g.Map(name)
+	if !ok {
+		return Map{}, fmt.Errorf("map %q does not exist in %s", name, g.Name)
+	}
+
+	if mode != "" {
+		if _, ok := g.GameType(mode); !ok {
+			return Map{}, fmt.Errorf("gametype %q does not exist in %s", mode, g.Name)
+		}
+	}
+
+	return m, nil
+}
+
+func normalize(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,139 @@
+package catalog
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	for _, code := range []string{"IW4", "iw4", " IW4 "} {
+		if g, ok := Lookup(code); !ok || g.Code != "IW4" {
+			t.Errorf("Lookup(%q) = %v, %v, want IW4", code, g, ok)
+		}
+	}
+	if _, ok := Lookup("IW9"); ok {
+		t.Error("Lookup(IW9) found a game")
+	}
+
+	games := Games()
+	if !slices.IsSorted(games) || !slices.Contains(games, "T6") {
+		t.Errorf("Games() = %q, want every game code sorted", games)
+	}
+}
+
+func TestMap(t *testing.T) {
+	tests := []struct {
+		game, name string
+		want       string
+	}{
+		{"IW4", "mp_rust", "mp_rust"},
+		{"IW4", "MP_RUST", "mp_rust"},
+		{"IW4", "Rust", "mp_rust"},
+		{"IW4", "karachi", "mp_checkpoint"},
+		{"IW4", "Sub Base", "mp_subbase"},
+		{"IW4", "subbase", "mp_subbase"},
+		{"IW4", "Trailer-Park", "mp_trailerpark"},
+		{"T6", "nuketown", "mp_nuketown_2020"},
+		{"T6", "Nuketown 2025", "mp_nuketown_2020"},
+		{"IW4", "nowhere", ""},
+		{"IW4", "", ""},
+		{"IW3", "Rust", ""},
+	}
+
+	for _, tt := range tests {
+		g, _ := Lookup(tt.game)
+		m, ok := g.Map(tt.name)
+		if ok != (tt.want != "") || m.Code != tt.want {
+			t.Errorf("%s Map(%q) = %q, %v, want %q", tt.game, tt.name, m.Code, ok, tt.want)
+		}
+	}
+}
+
+func TestGameType(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"war", "war"},
+		{"WAR", "war"},
+		{"Team Deathmatch", "war"},
+		{"free-for-all", "dm"},
+		{"gun", ""},
+	}
+
+	g, _ := Lookup("IW4")
+	for _, tt := range tests {
+		gt, ok := g.GameType(tt.name)
+		if ok != (tt.want != "") || gt.Code != tt.want {
+			t.Errorf("GameType(%q) = %q, %v, want %q", tt.name, gt.Code, ok, tt.want)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	tests := []struct {
+		got, want string
+	}{
+		{MapName("IW4", "mp_checkpoint"), "Karachi"},
+		{MapName("IW4", "mp_custom"), "mp_custom"},
+		{MapName("IW9", "mp_rust"), "mp_rust"},
+		{GameTypeName("IW5", "infect"), "Infected"},
+		{GameTypeName("IW5", "custom"), "custom"},
+		{GameTypeName("IW9", "war"), "war"},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+}
+
+func TestValidateMap(t *testing.T) {
+	tests := []struct {
+		game, name, mode string
+		want             string
+	}{
+		{"IW4", "rust", "", "mp_rust"},
+		{"IW4", "rust", "Team Deathmatch", "mp_rust"},
+		{"IW4", "mp_derail", "sd", "mp_derail"},
+		{"IW9", "rust", "", ""},
+		{"IW4", "nowhere", "", ""},
+		{"IW4", "rust", "zombies", ""},
+	}
+
+	for _, tt := range tests {
+		m, err := ValidateMap(tt.game, tt.name, tt.mode)
+		if (err == nil) != (tt.want != "") || m.Code != tt.want {
+			t.Errorf("ValidateMap(%q, %q, %q) = %q, %v, want %q", tt.game, tt.name, tt.mode, m.Code, err, tt.want)
+		}
+	}
+}
+
+// Every name must resolve to exactly one map, or lookups depend on order
+func TestNamesAreUnique(t *testing.T) {
+	for _, code := range Games() {
+		g, _ := Lookup(code)
+
+		seen := make(map[string]string)
+		for _, m := range g.Maps {
+			for _, name := range append([]string{m.Code, m.Name}, m.Aliases...) {
+				key := normalize(name)
+				if other, ok := seen[key]; ok && other != m.Code {
+					t.Errorf("%s: %q names both %s and %s", code, name, other, m.Code)
+				}
+				seen[key] = m.Code
+			}
+		}
+
+		types := make(map[string]string)
+		for _, gt := range g.GameTypes {
+			for _, name := range []string{gt.Code, gt.Name} {
+				key := normalize(name)
+				if other, ok := types[key]; ok && other != gt.Code {
+					t.Errorf("%s: %q names both gametypes %s and %s", code, name, other, gt.Code)
+				}
+				types[key] = gt.Code
+			}
+		}
+	}
+}
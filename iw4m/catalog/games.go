@@ -0,0 +1,312 @@
+package catalog
+
+func m(code, name, dlc string, aliases ...string) Map {
+	return Map{Code: code, Name: name, DLC: dlc, Aliases: aliases}
+}
+
+var games = map[string]*Game{
+	"IW3": {
+		Code: "IW3",
+		Name: "Call of Duty 4: Modern Warfare",
+		Maps: []Map{
+			m("mp_backlot", "Backlot", ""),
+			m("mp_bloc", "Bloc", ""),
+			m("mp_bog", "Bog", ""),
+			m("mp_cargoship", "Wet Work", ""),
+			m("mp_citystreets", "District", ""),
+			m("mp_convoy", "Ambush", ""),
+			m("mp_countdown", "Countdown", ""),
+			m("mp_crash", "Crash", ""),
+			m("mp_crash_snow", "Winter Crash", ""),
+			m("mp_crossfire", "Crossfire", ""),
+			m("mp_farm", "Downpour", ""),
+			m("mp_overgrown", "Overgrown", ""),
+			m("mp_pipeline", "Pipeline", ""),
+			m("mp_shipment", "Shipment", ""),
+			m("mp_showdown", "Showdown", ""),
+			m("mp_strike", "Strike", ""),
+			m("mp_vacant", "Vacant", ""),
+			m("mp_broadcast", "Broadcast", "Variety Map Pack"),
+			m("mp_carentan", "Chinatown", "Variety Map Pack"),
+			m("mp_creek", "Creek", "Variety Map Pack"),
+			m("mp_killhouse", "Killhouse", "Variety Map Pack"),
+		},
+		GameTypes: []GameType{
+			{"dm", "Free-for-all"},
+			{"war", "Team Deathmatch"},
+			{"sd", "Search and Destroy"},
+			{"sab", "Sabotage"},
+			{"dom", "Domination"},
+			{"koth", "Headquarters"},
+		},
+	},
+	"IW4": {
+		Code: "IW4",
+		Name: "Call of Duty: Modern Warfare 2",
+		Maps: []Map{
+			m("mp_afghan", "Afghan", ""),
+			m("mp_derail", "Derail", ""),
+			m("mp_estate", "Estate", ""),
+			m("mp_favela", "Favela", ""),
+			m("mp_highrise", "Highrise", ""),
+			m("mp_invasion", "Invasion", ""),
+			m("mp_checkpoint", "Karachi", ""),
+			m("mp_quarry", "Quarry", ""),
+			m("mp_rundown", "Rundown", ""),
+			m("mp_rust", "Rust", ""),
+			m("mp_boneyard", "Scrapyard", ""),
+			m("mp_nightshift", "Skidrow", ""),
+			m("mp_subbase", "Sub Base", ""),
+			m("mp_terminal", "Terminal", ""),
+			m("mp_underpass", "Underpass", ""),
+			m("mp_brecourt", "Wasteland", ""),
+			m("mp_complex", "Bailout", "Stimulus"),
+			m("mp_crash", "Crash", "Stimulus"),
+			m("mp_overgrown", "Overgrown", "Stimulus"),
+			m("mp_compact", "Salvage", "Stimulus"),
+			m("mp_storm", "Storm", "Stimulus"),
+			m("mp_abandon", "Carnival", "Resurgence"),
+			m("mp_fuel2", "Fuel", "Resurgence"),
+			m("mp_strike", "Strike", "Resurgence"),
+			m("mp_trailerpark", "Trailer Park", "Resurgence"),
+			m("mp_vacant", "Vacant", "Resurgence"),
+		},
+		GameTypes: []GameType{
+			{"dm", "Free-for-all"},
+			{"war", "Team Deathmatch"},
+			{"sd", "Search and Destroy"},
+			{"sab", "Sabotage"},
+			{"dom", "Domination"},
+			{"koth", "Headquarters"},
+			{"ctf", "Capture the Flag"},
+			{"dd", "Demolition"},
+			{"arena", "Arena"},
+			{"oneflag", "One-Flag CTF"},
+			{"gtnw", "Global Thermonuclear War"},
+			{"vip", "VIP"},
+		},
+	},
+	"IW5": {
+		Code: "IW5",
+		Name: "Call of Duty: Modern Warfare 3",
+		Maps: []Map{
+			m("mp_alpha", "Lockdown", ""),
+			m("mp_bootleg", "Bootleg", ""),
+			m("mp_bravo", "Mission", ""),
+			m("mp_carbon", "Carbon", ""),
+			m("mp_dome", "Dome", ""),
+			m("mp_exchange", "Downturn", ""),
+			m("mp_hardhat", "Hardhat", ""),
+			m("mp_interchange", "Interchange", ""),
+			m("mp_lambeth", "Fallen", ""),
+			m("mp_mogadishu", "Bakaara", ""),
+			m("mp_paris", "Resistance", ""),
+			m("mp_plaza2", "Arkaden", ""),
+			m("mp_radar", "Outpost", ""),
+			m("mp_seatown", "Seatown", ""),
+			m("mp_underground", "Underground", ""),
+			m("mp_village", "Village", ""),
+			m("mp_terminal_cls", "Terminal", "Terminal"),
+			m("mp_park", "Liberation", "Content Collection 1"),
+			m("mp_italy", "Piazza", "Content Collection 1"),
+			m("mp_overwatch", "Overwatch", "Content Collection 1"),
+			m("mp_morningwood", "Black Box", "Content Collection 1"),
+			m("mp_meteora", "Sanctuary", "Content Collection 2"),
+			m("mp_cement", "Foundation", "Content Collection 2"),
+			m("mp_qadeem", "Oasis", "Content Collection 2"),
+			m("mp_shipbreaker", "Decommission", "Content Collection 3"),
+			m("mp_roughneck", "Off Shore", "Content Collection 3"),
+			m("mp_moab", "Gulch", "Content Collection 3"),
+			m("mp_boardwalk", "Boardwalk", "Content Collection 3"),
+			m("mp_nola", "Parish", "Content Collection 3"),
+		},
+		GameTypes: []GameType{
+			{"dm", "Free-for-all"},
+			{"war", "Team Deathmatch"},
+			{"sd", "Search and Destroy"},
+			{"sab", "Sabotage"},
+			{"dom", "Domination"},
+			{"koth", "Headquarters"},
+			{"ctf", "Capture the Flag"},
+			{"dd", "Demolition"},
+			{"tdef", "Team Defender"},
+			{"conf", "Kill Confirmed"},
+			{"grnd", "Drop Zone"},
+			{"gun", "Gun Game"},
+			{"infect", "Infected"},
+			{"oic", "One in the Chamber"},
+			{"jugg", "Juggernaut"},
+			{"tjugg", "Team Juggernaut"},
+		},
+	},
+	"IW6": {
+		Code: "IW6",
+		Name: "Call of Duty: Ghosts",
+		Maps: []Map{
+			m("mp_prisonbreak", "Prison Break", ""),
+			m("mp_dart", "Octane", ""),
+			m("mp_lonestar", "Tremor", ""),
+			m("mp_frag", "Freight", ""),
+			m("mp_snow", "Whiteout", ""),
+			m("mp_fahrenheit", "Stormfront", ""),
+			m("mp_hashima", "Siege", ""),
+			m("mp_warhawk", "Warhawk", ""),
+			m("mp_sovereign", "Sovereign", ""),
+			m("mp_zebra", "Overlord", ""),
+			m("mp_skeleton", "Stonehaven", ""),
+			m("mp_chasm", "Chasm", ""),
+			m("mp_flooded", "Flooded", ""),
+			m("mp_strikezone", "Strikezone", ""),
+		},
+		GameTypes: []GameType{
+			{"dm", "Free-for-all"},
+			{"war", "Team Deathmatch"},
+			{"sd", "Search and Destroy"},
+			{"dom", "Domination"},
+			{"conf", "Kill Confirmed"},
+			{"sr", "Search and Rescue"},
+			{"blitz", "Blitz"},
+			{"cranked", "Cranked"},
+			{"grind", "Grind"},
+			{"infect", "Infected"},
+			{"sotf", "Hunted"},
+			{"siege", "Reinforce"},
+		},
+	},
+	"T4": {
+		Code: "T4",
+		Name: "Call of Duty: World at War",
+		Maps: []Map{
+			m("mp_airfield", "Airfield", ""),
+			m("mp_asylum", "Asylum", ""),
+			m("mp_castle", "Castle", ""),
+			m("mp_shrine", "Cliffside", ""),
+			m("mp_courtyard", "Courtyard", ""),
+			m("mp_dome", "Dome", ""),
+			m("mp_downfall", "Downfall", ""),
+			m("mp_hangar", "Hangar", ""),
+			m("mp_makin", "Makin", ""),
+			m("mp_outskirts", "Outskirts", ""),
+			m("mp_roundhouse", "Roundhouse", ""),
+			m("mp_seelow", "Seelow", ""),
+			m("mp_suburban", "Upheaval", ""),
+			m("mp_makin_day", "Makin Day", "Map Pack 1"),
+			m("mp_nachtfeuer", "Nightfire", "Map Pack 1"),
+			m("mp_subway", "Station", "Map Pack 1"),
+			m("mp_kneedeep", "Knee Deep", "Map Pack 1"),
+			m("mp_docks", "Sub Pens", "Map Pack 2"),
+			m("mp_stalingrad", "Corrosion", "Map Pack 2"),
+			m("mp_kwai", "Banzai", "Map Pack 2"),
+			m("mp_bgate", "Breach", "Map Pack 3"),
+			m("mp_vodka", "Revolution", "Map Pack 3"),
+		},
+		GameTypes: []GameType{
+			{"dm", "Free-for-all"},
+			{"tdm", "Team Deathmatch"},
+			{"sd", "Search and Destroy"},
+			{"sab", "Sabotage"},
+			{"dom", "Domination"},
+			{"koth", "Headquarters"},
+			{"ctf", "Capture the Flag"},
+			{"twar", "War"},
+		},
+	},
+	"T5": {
+		Code: "T5",
+		Name: "Call of Duty: Black Ops",
+		Maps: []Map{
+			m("mp_array", "Array", ""),
+			m("mp_cairo", "Havana", ""),
+			m("mp_cosmodrome", "Launch", ""),
+			m("mp_cracked", "Cracked", ""),
+			m("mp_crisis", "Crisis", ""),
+			m("mp_duga", "Grid", ""),
+			m("mp_firingrange", "Firing Range", ""),
+			m("mp_hanoi", "Hanoi", ""),
+			m("mp_havoc", "Jungle", ""),
+			m("mp_mountain", "Summit", ""),
+			m("mp_nuked", "Nuketown", ""),
+			m("mp_radiation", "Radiation", ""),
+			m("mp_russianbase", "WMD", ""),
+			m("mp_villa", "Villa", ""),
+			m("mp_berlinwall2", "Berlin Wall", "First Strike"),
+			m("mp_discovery", "Discovery", "First Strike"),
+			m("mp_kowloon", "Kowloon", "First Strike"),
+			m("mp_stadium", "Stadium", "First Strike"),
+			m("mp_gridlock", "Convoy", "Escalation"),
+			m("mp_hotel", "Hotel", "Escalation"),
+			m("mp_outskirts", "Stockpile", "Escalation"),
+			m("mp_zoo", "Zoo", "Escalation"),
+			m("mp_area51", "Hangar 18", "Annihilation"),
+			m("mp_drivein", "Drive-In", "Annihilation"),
+			m("mp_golfcourse", "Hazard", "Annihilation"),
+			m("mp_silo", "Silo", "Annihilation"),
+		},
+		GameTypes: []GameType{
+			{"dm", "Free-for-all"},
+			{"tdm", "Team Deathmatch"},
+			{"sd", "Search and Destroy"},
+			{"sab", "Sabotage"},
+			{"dom", "Domination"},
+			{"koth", "Headquarters"},
+			{"ctf", "Capture the Flag"},
+			{"dem", "Demolition"},
+			{"gun", "Gun Game"},
+			{"hlnd", "Sticks and Stones"},
+			{"oic", "One in the Chamber"},
+			{"shrp", "Sharpshooter"},
+		},
+	},
+	"T6": {
+		Code: "T6",
+		Name: "Call of Duty: Black Ops II",
+		Maps: []Map{
+			m("mp_la", "Aftermath", ""),
+			m("mp_dockside", "Cargo", ""),
+			m("mp_carrier", "Carrier", ""),
+			m("mp_drone", "Drone", ""),
+			m("mp_express", "Express", ""),
+			m("mp_hijacked", "Hijacked", ""),
+			m("mp_meltdown", "Meltdown", ""),
+			m("mp_overflow", "Overflow", ""),
+			m("mp_nightclub", "Plaza", ""),
+			m("mp_raid", "Raid", ""),
+			m("mp_slums", "Slums", ""),
+			m("mp_village", "Standoff", ""),
+			m("mp_turbine", "Turbine", ""),
+			m("mp_socotra", "Yemen", ""),
+			m("mp_nuketown_2020", "Nuketown 2025", "", "nuketown"),
+			m("mp_downhill", "Downhill", "Revolution"),
+			m("mp_hydro", "Hydro", "Revolution"),
+			m("mp_mirage", "Mirage", "Revolution"),
+			m("mp_skate", "Grind", "Revolution"),
+			m("mp_concert", "Encore", "Uprising"),
+			m("mp_magma", "Magma", "Uprising"),
+			m("mp_studio", "Studio", "Uprising"),
+			m("mp_vertigo", "Vertigo", "Uprising"),
+			m("mp_bridge", "Detour", "Vengeance"),
+			m("mp_castaway", "Cove", "Vengeance"),
+			m("mp_paintball", "Rush", "Vengeance"),
+			m("mp_uplink", "Uplink", "Vengeance"),
+			m("mp_dig", "Dig", "Apocalypse"),
+			m("mp_frostbite", "Frost", "Apocalypse"),
+			m("mp_pod", "Pod", "Apocalypse"),
+			m("mp_takeoff", "Takeoff", "Apocalypse"),
+		},
+		GameTypes: []GameType{
+			{"dm", "Free-for-all"},
+			{"tdm", "Team Deathmatch"},
+			{"sd", "Search and Destroy"},
+			{"dom", "Domination"},
+			{"koth", "Hardpoint"},
+			{"hq", "Headquarters"},
+			{"ctf", "Capture the Flag"},
+			{"dem", "Demolition"},
+			{"conf", "Kill Confirmed"},
+			{"sas", "Sticks and Stones"},
+			{"gun", "Gun Game"},
+			{"oic", "One in the Chamber"},
+			{"shrp", "Sharpshooter"},
+		},
+	},
+}
@@ -0,0 +1,128 @@
+package chat
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+// Source is the part of the server API the reader polls
+type Source interface {
+	ReadChat() ([]server.Chat, error)
+}
+
+type Message struct {
+	// Seq increases by one for every message the reader yields
+	Seq uint64 `json:"seq"`
+	// Time the message was first seen
+	Time    time.Time `json:"time"`
+	Sender  string    `json:"sender"`
+	Message string    `json:"message"`
+}
+
+// Reader turns the rolling chat buffer on the webfront homepage into a
+// stream of new messages. Each poll is aligned against the previous one
+// and only lines that are not part of the common subsequence are new
+type Reader struct {
+	source Source
+
+	mu     sync.Mutex
+	buffer []server.Chat
+	primed bool
+	seq    uint64
+	// Gaps counts polls where nothing overlapped the previous buffer, so
+	// messages may have been missed between them
+	gaps int
+}
+
+// Create a new Reader. The first Read only records the current buffer, so
+// old messages are not reported as new
+func NewReader(source Source) *Reader {
+	return &Reader{source: source}
+}
+
+// Fetch the chat and return the messages not seen before
+func (r *Reader) Read() ([]Message, error) {
+	chat, err := r.source.ReadChat()
+	if err != nil {
+		return nil, err
+	}
+	return r.Advance(chat, time.Now()), nil
+}
+
+// Align a chat buffer fetched at now against the previous one and return
+// the new messages in order
+func (r *Reader) Advance(chat []server.Chat, now time.Time) []Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fresh := unmatched(r.buffer, chat)
+	if r.primed && len(r.buffer) > 0 && len(chat) > 0 && len(fresh) == len(chat) {
+		r.gaps++
+	}
+
+	var messages []Message
+	if r.primed {
+		for _, i := range fresh {
+			r.seq++
+			messages = append(messages, Message{
+				Seq:     r.seq,
+				Time:    now,
+				Sender:  chat[i].Sender,
+				Message: chat[i].Message,
+			})
+		}
+	}
+
+	r.buffer = chat
+	r.primed = true
+	return messages
+}
+
+// Report how many polls found no overlap with the previous buffer
+func (r *Reader) Gaps() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.gaps
+}
+
+// Return the indexes of current that are not part of the longest common
+// subsequence of previous and current. Lines that scrolled out of the
+// buffer simply drop out of previous, and new lines, wherever the webfront
+// inserts them, are left unmatched. Ties are broken towards matching
+// earlier lines, so repeated lines at the end count as new
+func unmatched(previous, current []server.Chat) []int {
+	n, m := len(previous), len(current)
+
+	// lcs[i][j] is the LCS length of previous[i:] and current[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if previous[i] == current[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var fresh []int
+	i, j := 0, 0
+	for j < m {
+		switch {
+		case i < n && previous[i] == current[j] && lcs[i][j] == lcs[i+1][j+1]+1:
+			i++
+			j++
+		case i < n && lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			fresh = append(fresh, j)
+			j++
+		}
+	}
+	return fresh
+}
@@ -0,0 +1,83 @@
+package chat
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+// Build a chat buffer from "sender: message" lines
+func buffer(lines ...string) []server.Chat {
+	chat := make([]server.Chat, len(lines))
+	for i, line := range lines {
+		sender, message, _ := strings.Cut(line, ": ")
+		chat[i] = server.Chat{Sender: sender, Message: message}
+	}
+	return chat
+}
+
+func TestUnmatched(t *testing.T) {
+	tests := []struct {
+		name              string
+		previous, current []server.Chat
+		want              []int
+	}{
+		{"empty", nil, nil, nil},
+		{"first poll", nil, buffer("a: hi", "b: yo"), []int{0, 1}},
+		{"unchanged", buffer("a: hi", "b: yo"), buffer("a: hi", "b: yo"), nil},
+		{"appended", buffer("a: hi", "b: yo"), buffer("a: hi", "b: yo", "c: gg"), []int{2}},
+		{"scrolled", buffer("a: hi", "b: yo", "c: gg"), buffer("b: yo", "c: gg", "d: ez"), []int{2}},
+		{"prepended", buffer("a: hi", "b: yo"), buffer("c: gg", "a: hi", "b: yo"), []int{0}},
+		{"repeated line", buffer("a: gg", "b: yo"), buffer("a: gg", "b: yo", "a: gg"), []int{2}},
+		{"repeated twice", buffer("a: gg"), buffer("a: gg", "a: gg", "a: gg"), []int{1, 2}},
+		{"no overlap", buffer("a: hi"), buffer("b: yo", "c: gg"), []int{0, 1}},
+		{"emptied", buffer("a: hi"), nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unmatched(tt.previous, tt.current); !slices.Equal(got, tt.want) {
+				t.Errorf("unmatched = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReaderAdvance(t *testing.T) {
+	r := NewReader(nil)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		buffer []server.Chat
+		want   []string
+		gaps   int
+	}{
+		{buffer("a: old", "b: older"), nil, 0},
+		{buffer("a: old", "b: older", "c: new"), []string{"new"}, 0},
+		{buffer("b: older", "c: new", "c: new", "d: gg"), []string{"new", "gg"}, 0},
+		{buffer("x: missed", "y: everything"), []string{"missed", "everything"}, 1},
+	}
+
+	var seq uint64
+	for i, step := range steps {
+		messages := r.Advance(step.buffer, now)
+
+		var got []string
+		for _, m := range messages {
+			got = append(got, m.Message)
+			seq++
+			if m.Seq != seq {
+				t.Errorf("step %d: seq %d, want %d", i, m.Seq, seq)
+			}
+		}
+		if !slices.Equal(got, step.want) {
+			t.Errorf("step %d: messages %q, want %q", i, got, step.want)
+		}
+		if r.Gaps() != step.gaps {
+			t.Errorf("step %d: %d gaps, want %d", i, r.Gaps(), step.gaps)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package chatqueue
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+type Priority int
+
+const (
+	Low Priority = iota
+	Normal
+	High
+)
+
+// ErrQueueFull is returned when a server's queue is full and the message
+// does not outrank anything already queued
+var ErrQueueFull = errors.New("chat queue is full")
+
+// ErrClosed is returned when enqueueing on a closed queue
+var ErrClosed = errors.New("chat queue is closed")
+
+// Sender delivers a single command to a server
+type Sender func(serverID, command string) error
+
+type Options struct {
+	// Minimum time between two commands on the same server
+	Interval time.Duration
+	// Maximum number of commands waiting per server
+	Capacity int
+	// Called when delivering a message fails
+	OnError func(serverID, command string, err error)
+}
+
+// A message is one or more commands delivered in order, such as the lines
+// of a split chat message
+type message struct {
+	commands []string
+	priority Priority
+	queued   time.Time
+}
+
+type lane struct {
+	items []*message
+	wake  chan struct{}
+}
+
+// Queue paces outgoing chat per server so bots cannot flood a game server.
+// Each server gets its own lane and worker, started on first use
+type Queue struct {
+	send Sender
+	opts Options
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	lanes  map[string]*lane
+	closed bool
+}
+
+// Create a new Queue delivering messages through send
+func New(send Sender, opts Options) *Queue {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	if opts.Capacity <= 0 {
+		opts.Capacity = 50
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Queue{
+		send:   send,
+		opts:   opts,
+		ctx:    ctx,
+		cancel: cancel,
+		lanes:  make(map[string]*lane),
+	}
+}
+
+// Queue a command for a server. A command identical to one already waiting
+// on the same server is coalesced into it, keeping the higher priority.
+// When the lane is full the oldest lowest priority messages are dropped to
+// make room if they rank below the new one, otherwise ErrQueueFull is
+// returned
+func (q *Queue) Enqueue(serverID, command string, priority Priority) error {
+	return q.EnqueueAll(serverID, []string{command}, priority)
+}
+
+// Queue commands for a server as one message, such as the lines of a split
+// chat message. The commands are queued all or nothing, delivered in order
+// without other messages in between and never coalesced with each other.
+// The message is coalesced only with an identical message
+func (q *Queue) EnqueueAll(serverID string, commands []string, priority Priority) error {
+	if len(commands) == 0 {
+		return nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrClosed
+	}
+	if len(commands) > q.opts.Capacity {
+		return ErrQueueFull
+	}
+
+	l, ok := q.lanes[serverID]
+	if !ok {
+		l = &lane{wake: make(chan struct{}, 1)}
+		q.lanes[serverID] = l
+		q.wg.Add(1)
+		go q.work(serverID, l)
+	}
+
+	for _, m := range l.items {
+		if slices.Equal(m.commands, commands) {
+			m.priority = max(m.priority, priority)
+			return nil
+		}
+	}
+
+	// Pick victims first so nothing is dropped when there is no room anyway
+	free := q.opts.Capacity - l.pending()
+	var victims []*message
+	for free < len(commands) {
+		var victim *message
+		for _, m := range l.items {
+			if m.priority < priority && !slices.Contains(victims, m) && (victim == nil || m.priority < victim.priority) {
+				victim = m
+			}
+		}
+		if victim == nil {
+			return ErrQueueFull
+		}
+		victims = append(victims, victim)
+		free += len(victim.commands)
+	}
+	l.items = slices.DeleteFunc(l.items, func(m *message) bool { return slices.Contains(victims, m) })
+
+	l.items = append(l.items, &message{
+		commands: slices.Clone(commands),
+		priority: priority,
+		queued:   time.Now(),
+	})
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (l *lane) pending() int {
+	n := 0
+	for _, m := range l.items {
+		n += len(m.commands)
+	}
+	return n
+}
+
+// Report how many commands are waiting for a server
+func (q *Queue) Pending(serverID string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if l, ok := q.lanes[serverID]; ok {
+		return l.pending()
+	}
+	return 0
+}
+
+// Report whether a server's lane is at capacity, so callers can back off
+// before enqueueing
+func (q *Queue) Full(serverID string) bool {
+	return q.Pending(serverID) >= q.opts.Capacity
+}
+
+// Stop every worker. Messages still waiting are discarded
+func (q *Queue) Close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+
+	q.cancel()
+	q.wg.Wait()
+}
+
+func (q *Queue) work(serverID string, l *lane) {
+	defer q.wg.Done()
+
+	for {
+		m := q.next(l)
+		if m == nil {
+			select {
+			case <-q.ctx.Done():
+				return
+			case <-l.wake:
+				continue
+			}
+		}
+
+		for _, command := range m.commands {
+			if err := q.send(serverID, command); err != nil && q.opts.OnError != nil {
+				q.opts.OnError(serverID, command, err)
+			}
+
+			select {
+			case <-q.ctx.Done():
+				return
+			case <-time.After(q.opts.Interval):
+			}
+		}
+	}
+}
+
+// Pop the oldest message of the highest priority
+func (q *Queue) next(l *lane) *message {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(l.items) == 0 {
+		return nil
+	}
+
+	best := 0
+	for i, m := range l.items {
+		if m.priority > l.items[best].priority {
+			best = i
+		}
+	}
+
+	m := l.items[best]
+	l.items = append(l.items[:best], l.items[best+1:]...)
+	return m
+}
@@ -0,0 +1,113 @@
+package chatqueue
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Start a queue whose worker is stuck sending a first command, so later
+// messages stay queued until drain releases it and waits for n commands
+func blockedQueue(t *testing.T, capacity int) (*Queue, func(n int) []string) {
+	t.Helper()
+
+	var (
+		mu      sync.Mutex
+		sent    []string
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+	q := New(func(serverID, command string) error {
+		mu.Lock()
+		sent = append(sent, command)
+		first := len(sent) == 1
+		mu.Unlock()
+		if first {
+			close(started)
+			<-release
+		}
+		return nil
+	}, Options{Interval: time.Millisecond, Capacity: capacity})
+
+	if err := q.Enqueue("1", "block", High); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	return q, func(n int) []string {
+		close(release)
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			mu.Lock()
+			done := len(sent) > n
+			mu.Unlock()
+			if done || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		q.Close()
+
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(sent[1:])
+	}
+}
+
+func TestEnqueueAllIsAllOrNothing(t *testing.T) {
+	q, drain := blockedQueue(t, 3)
+
+	if err := q.EnqueueAll("1", []string{"a", "b"}, Normal); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.EnqueueAll("1", []string{"c", "d"}, Normal); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("EnqueueAll = %v, want ErrQueueFull", err)
+	}
+	if got := q.Pending("1"); got != 2 {
+		t.Fatalf("Pending = %d after rejected message, want 2", got)
+	}
+
+	if got, want := drain(2), []string{"a", "b"}; !slices.Equal(got, want) {
+		t.Errorf("sent %q, want %q", got, want)
+	}
+}
+
+func TestEnqueueAllEvictsWholeMessages(t *testing.T) {
+	q, drain := blockedQueue(t, 4)
+
+	if err := q.EnqueueAll("1", []string{"low 1", "low 2"}, Low); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.EnqueueAll("1", []string{"normal 1", "normal 2"}, Normal); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.EnqueueAll("1", []string{"high 1", "high 2"}, High); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"high 1", "high 2", "normal 1", "normal 2"}
+	if got := drain(len(want)); !slices.Equal(got, want) {
+		t.Errorf("sent %q, want %q", got, want)
+	}
+}
+
+func TestEnqueueAllKeepsRepeatedLines(t *testing.T) {
+	q, drain := blockedQueue(t, 10)
+
+	if err := q.EnqueueAll("1", []string{"same", "same"}, Normal); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue("1", "other", Normal); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue("1", "other", High); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"other", "same", "same"}
+	if got := drain(len(want)); !slices.Equal(got, want) {
+		t.Errorf("sent %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,248 @@
+package enforce
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+// Backend is the part of the server API enforcement needs
+type Backend interface {
+	Status() ([]server.ServerStatus, error)
+	TellOn(serverID, target, message string) error
+	Kick(serverID, target, reason string) error
+}
+
+type tracker struct {
+	pingSince  time.Time
+	score      int
+	scoreSince time.Time
+	warnings   map[Violation]int
+	lastWarn   map[Violation]time.Time
+	seen       time.Time
+}
+
+// Engine warns and kicks players with a sustained high ping or who are AFK
+type Engine struct {
+	backend Backend
+	cfg     Config
+	players map[string]*tracker
+}
+
+// Create a new enforcement engine
+func New(backend Backend, cfg Config) *Engine {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 15 * time.Second
+	}
+	if cfg.Location == nil {
+		cfg.Location = time.Local
+	}
+
+	for i := range cfg.Rules {
+		p := &cfg.Rules[i].Policy
+		if p.Warnings <= 0 {
+			p.Warnings = 1
+		}
+		if p.Grace <= 0 {
+			p.Grace = time.Minute
+		}
+		if p.Exempt == nil {
+			p.Exempt = slices.Clone(server.StockLevels[server.LevelRank("Trusted"):])
+		}
+		if p.PingWarning == "" {
+			p.PingWarning = server.Colorize("{yellow}Your ping is too high, you will be kicked if it stays above " + strconv.Itoa(p.MaxPing))
+		}
+		if p.PingKick == "" {
+			p.PingKick = "Ping too high"
+		}
+		if p.AFKWarning == "" {
+			p.AFKWarning = server.Colorize("{yellow}You appear to be AFK and will be kicked soon")
+		}
+		if p.AFKKick == "" {
+			p.AFKKick = "AFK"
+		}
+	}
+
+	return &Engine{
+		backend: backend,
+		cfg:     cfg,
+		players: make(map[string]*tracker),
+	}
+}
+
+// Poll Status() and enforce until ctx is cancelled
+func (e *Engine) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		status, err := e.backend.Status()
+		if err != nil {
+			e.fail(err)
+		} else {
+			e.Evaluate(status, time.Now())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check one Status() snapshot taken at now
+func (e *Engine) Evaluate(status []server.ServerStatus, now time.Time) {
+	for _, s := range status {
+		serverID := strconv.Itoa(s.ID)
+		policy, ok := e.policy(serverID, now)
+		if !ok {
+			continue
+		}
+
+		for _, p := range s.Players {
+			if policy.exempt(p.Level) || strings.EqualFold(p.State, "connecting") {
+				continue
+			}
+
+			key := serverID + "/" + strconv.Itoa(p.ClientNumber) + "/" + p.Name
+			t, ok := e.players[key]
+			if !ok {
+				t = &tracker{
+					score:      p.Score,
+					scoreSince: now,
+					warnings:   make(map[Violation]int),
+					lastWarn:   make(map[Violation]time.Time),
+				}
+				e.players[key] = t
+			}
+			t.seen = now
+
+			if e.checkPing(serverID, p, t, policy, now) {
+				delete(e.players, key)
+				continue
+			}
+			if e.checkAFK(serverID, p, t, policy, now) {
+				delete(e.players, key)
+			}
+		}
+	}
+
+	// Forget players that left
+	for key, t := range e.players {
+		if t.seen != now {
+			delete(e.players, key)
+		}
+	}
+}
+
+// Returns true when the player was kicked
+func (e *Engine) checkPing(serverID string, p server.PlayerStatus, t *tracker, policy Policy, now time.Time) bool {
+	if policy.MaxPing <= 0 || p.Ping <= policy.MaxPing {
+		t.pingSince = time.Time{}
+		t.warnings[HighPing] = 0
+		return false
+	}
+
+	if t.pingSince.IsZero() {
+		t.pingSince = now
+	}
+	if now.Sub(t.pingSince) < policy.PingWindow {
+		return false
+	}
+	return e.escalate(serverID, p, t, policy, HighPing, policy.PingWarning, policy.PingKick, now)
+}
+
+// Returns true when the player was kicked
+func (e *Engine) checkAFK(serverID string, p server.PlayerStatus, t *tracker, policy Policy, now time.Time) bool {
+	if policy.AFKAfter <= 0 {
+		return false
+	}
+
+	if p.Score != t.score {
+		t.score = p.Score
+		t.scoreSince = now
+		t.warnings[AFK] = 0
+		return false
+	}
+
+	// Players who just connected have not had a chance to score yet
+	if time.Duration(p.ConnectionTime)*time.Second < policy.AFKAfter {
+		return false
+	}
+	if now.Sub(t.scoreSince) < policy.AFKAfter {
+		return false
+	}
+	return e.escalate(serverID, p, t, policy, AFK, policy.AFKWarning, policy.AFKKick, now)
+}
+
+func (e *Engine) escalate(serverID string, p server.PlayerStatus, t *tracker, policy Policy, v Violation, warning, reason string, now time.Time) bool {
+	if last := t.lastWarn[v]; !last.IsZero() && now.Sub(last) < policy.Grace {
+		return false
+	}
+
+	target := server.BySlot(p.ClientNumber)
+	action := Action{Time: now, ServerID: serverID, Player: p.Name, Violation: v}
+
+	if t.warnings[v] < policy.Warnings {
+		t.warnings[v]++
+		t.lastWarn[v] = now
+		if err := e.backend.TellOn(serverID, target, warning); err != nil {
+			e.fail(err)
+			return false
+		}
+		e.report(action)
+		return false
+	}
+
+	if err := e.backend.Kick(serverID, target, reason); err != nil {
+		e.fail(err)
+		return false
+	}
+	action.Kicked = true
+	e.report(action)
+	return true
+}
+
+func (e *Engine) policy(serverID string, now time.Time) (Policy, bool) {
+	hour := now.In(e.cfg.Location).Hour()
+	for _, r := range e.cfg.Rules {
+		if len(r.Servers) > 0 && !slices.Contains(r.Servers, serverID) {
+			continue
+		}
+		if r.covers(hour) {
+			return r.Policy, true
+		}
+	}
+	return Policy{}, false
+}
+
+func (r Rule) covers(hour int) bool {
+	switch {
+	case r.From == r.To:
+		return true
+	case r.From < r.To:
+		return hour >= r.From && hour < r.To
+	}
+	return hour >= r.From || hour < r.To
+}
+
+func (p Policy) exempt(level string) bool {
+	return slices.ContainsFunc(p.Exempt, func(l string) bool { return server.SameLevel(l, level) })
+}
+
+func (e *Engine) report(a Action) {
+	if e.cfg.OnAction != nil {
+		e.cfg.OnAction(a)
+	}
+}
+
+func (e *Engine) fail(err error) {
+	if e.cfg.OnError != nil {
+		e.cfg.OnError(err)
+	}
+}
@@ -0,0 +1,229 @@
+package enforce
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/mock"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+var start = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestCovers(t *testing.T) {
+	tests := []struct {
+		from, to int
+		in, out  []int
+	}{
+		{0, 0, []int{0, 12, 23}, nil},
+		{8, 8, []int{0, 7, 8, 23}, nil},
+		{8, 20, []int{8, 12, 19}, []int{0, 7, 20, 23}},
+		{22, 6, []int{22, 23, 0, 5}, []int{6, 12, 21}},
+		{23, 0, []int{23}, []int{0, 22}},
+		{0, 1, []int{0}, []int{1, 23}},
+	}
+
+	for _, tt := range tests {
+		r := Rule{From: tt.from, To: tt.to}
+		for _, hour := range tt.in {
+			if !r.covers(hour) {
+				t.Errorf("Rule{%d, %d} does not cover %d", tt.from, tt.to, hour)
+			}
+		}
+		for _, hour := range tt.out {
+			if r.covers(hour) {
+				t.Errorf("Rule{%d, %d} covers %d", tt.from, tt.to, hour)
+			}
+		}
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	cfg := Config{
+		Location: time.UTC,
+		Rules: []Rule{
+			{Servers: []string{"2"}, Policy: Policy{MaxPing: 100}},
+			{From: 22, To: 6, Policy: Policy{MaxPing: 150}},
+			{From: 8, To: 18, Policy: Policy{MaxPing: 200}},
+		},
+	}
+	e := New(nil, cfg)
+
+	tests := []struct {
+		serverID string
+		hour     int
+		want     int
+		ok       bool
+	}{
+		{"2", 12, 100, true},
+		{"2", 23, 100, true},
+		{"1", 23, 150, true},
+		{"1", 3, 150, true},
+		{"1", 12, 200, true},
+		{"1", 20, 0, false},
+	}
+
+	for _, tt := range tests {
+		now := time.Date(2024, 3, 1, tt.hour, 30, 0, 0, time.UTC)
+		p, ok := e.policy(tt.serverID, now)
+		if ok != tt.ok || p.MaxPing != tt.want {
+			t.Errorf("policy(%s, %02d:30) = %d, %v, want %d, %v", tt.serverID, tt.hour, p.MaxPing, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+type tick struct {
+	after  time.Duration
+	player *server.PlayerStatus
+	want   string
+}
+
+func player(ping, score, connected int) *server.PlayerStatus {
+	return &server.PlayerStatus{Name: "bob", ClientNumber: 3, Level: "User", Ping: ping, Score: score, ConnectionTime: connected}
+}
+
+// Feed one player through a series of snapshots and check the command sent
+// after each of them
+func run(t *testing.T, policy Policy, ticks []tick) {
+	t.Helper()
+	backend := mock.NewServer()
+	var actions []Action
+	e := New(backend, Config{
+		Location: time.UTC,
+		Rules:    []Rule{{Policy: policy}},
+		OnAction: func(a Action) { actions = append(actions, a) },
+	})
+
+	for i, tk := range ticks {
+		st := server.ServerStatus{ID: 1}
+		if tk.player != nil {
+			st.Players = []server.PlayerStatus{*tk.player}
+		}
+
+		backend.Reset()
+		e.Evaluate([]server.ServerStatus{st}, start.Add(tk.after))
+
+		got := ""
+		if executed := backend.Executed(); len(executed) > 0 {
+			got = executed[0].Command
+		}
+		if got != tk.want {
+			t.Errorf("tick %d at %v: sent %q, want %q", i, tk.after, got, tk.want)
+		}
+	}
+
+	sent := 0
+	for _, tk := range ticks {
+		if tk.want != "" {
+			sent++
+		}
+	}
+	if len(actions) != sent {
+		t.Errorf("reported %d actions, want %d", len(actions), sent)
+	}
+}
+
+const (
+	pingWarning = "!privatemessage 3 ^3Your ping is too high, you will be kicked if it stays above 200"
+	pingKick    = "!kick 3 Ping too high"
+	afkWarning  = "!privatemessage 3 ^3You appear to be AFK and will be kicked soon"
+	afkKick     = "!kick 3 AFK"
+)
+
+func TestPing(t *testing.T) {
+	policy := Policy{MaxPing: 200, PingWindow: 30 * time.Second}
+
+	t.Run("warn then kick", func(t *testing.T) {
+		run(t, policy, []tick{
+			{0, player(300, 0, 0), ""},
+			{20 * time.Second, player(300, 0, 0), ""},
+			{30 * time.Second, player(300, 0, 0), pingWarning},
+			{60 * time.Second, player(300, 0, 0), ""},
+			{90 * time.Second, player(300, 0, 0), pingKick},
+		})
+	})
+
+	t.Run("ping recovers within the window", func(t *testing.T) {
+		run(t, policy, []tick{
+			{0, player(300, 0, 0), ""},
+			{20 * time.Second, player(100, 0, 0), ""},
+			{40 * time.Second, player(300, 0, 0), ""},
+			{60 * time.Second, player(300, 0, 0), ""},
+			{70 * time.Second, player(300, 0, 0), pingWarning},
+		})
+	})
+
+	t.Run("ping recovers after a warning", func(t *testing.T) {
+		run(t, policy, []tick{
+			{0, player(300, 0, 0), ""},
+			{30 * time.Second, player(300, 0, 0), pingWarning},
+			{60 * time.Second, player(100, 0, 0), ""},
+			{90 * time.Second, player(300, 0, 0), ""},
+			{110 * time.Second, player(300, 0, 0), ""},
+			{120 * time.Second, player(300, 0, 0), pingWarning},
+		})
+	})
+
+	t.Run("several warnings", func(t *testing.T) {
+		run(t, Policy{MaxPing: 200, Warnings: 2, Grace: 10 * time.Second}, []tick{
+			{0, player(300, 0, 0), pingWarning},
+			{5 * time.Second, player(300, 0, 0), ""},
+			{10 * time.Second, player(300, 0, 0), pingWarning},
+			{20 * time.Second, player(300, 0, 0), pingKick},
+		})
+	})
+
+	t.Run("leaving forgets the player", func(t *testing.T) {
+		run(t, policy, []tick{
+			{0, player(300, 0, 0), ""},
+			{20 * time.Second, nil, ""},
+			{30 * time.Second, player(300, 0, 0), ""},
+			{60 * time.Second, player(300, 0, 0), pingWarning},
+		})
+	})
+
+	t.Run("exempt level", func(t *testing.T) {
+		admin := player(300, 0, 0)
+		admin.Level = "Administrator"
+		run(t, policy, []tick{{0, admin, ""}, {60 * time.Second, admin, ""}})
+	})
+
+	t.Run("connecting", func(t *testing.T) {
+		connecting := player(999, 0, 0)
+		connecting.State = "Connecting"
+		run(t, policy, []tick{{0, connecting, ""}, {60 * time.Second, connecting, ""}})
+	})
+}
+
+func TestAFK(t *testing.T) {
+	policy := Policy{AFKAfter: 2 * time.Minute}
+
+	t.Run("warn then kick", func(t *testing.T) {
+		run(t, policy, []tick{
+			{0, player(50, 10, 600), ""},
+			{time.Minute, player(50, 10, 660), ""},
+			{2 * time.Minute, player(50, 10, 720), afkWarning},
+			{150 * time.Second, player(50, 10, 750), ""},
+			{3 * time.Minute, player(50, 10, 780), afkKick},
+		})
+	})
+
+	t.Run("scoring resets", func(t *testing.T) {
+		run(t, policy, []tick{
+			{0, player(50, 10, 600), ""},
+			{2 * time.Minute, player(50, 10, 720), afkWarning},
+			{150 * time.Second, player(50, 20, 750), ""},
+			{4 * time.Minute, player(50, 20, 840), ""},
+			{270 * time.Second, player(50, 20, 870), afkWarning},
+		})
+	})
+
+	t.Run("just connected", func(t *testing.T) {
+		run(t, policy, []tick{
+			{0, player(50, 0, 0), ""},
+			{time.Minute, player(50, 0, 60), ""},
+			{2 * time.Minute, player(50, 0, 110), ""},
+			{130 * time.Second, player(50, 0, 130), afkWarning},
+		})
+	})
+}
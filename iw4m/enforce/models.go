@@ -0,0 +1,58 @@
+package enforce
+
+import "time"
+
+type Policy struct {
+	// Ping above MaxPing for PingWindow is a violation, 0 disables it
+	MaxPing    int
+	PingWindow time.Duration
+	// A score unchanged for AFKAfter is a violation, 0 disables it
+	AFKAfter time.Duration
+	// Warnings sent before kicking, default 1
+	Warnings int
+	// Time between a warning and the next step, default 1m
+	Grace time.Duration
+	// Levels never enforced against, default Trusted and above
+	Exempt []string
+
+	PingWarning string
+	PingKick    string
+	AFKWarning  string
+	AFKKick     string
+}
+
+// Rule applies a policy to some servers during part of the day
+type Rule struct {
+	// Servers the rule applies to, empty matches every server
+	Servers []string
+	// Hours of the day [From, To) in the configured location. From == To
+	// matches the whole day and To < From wraps past midnight
+	From, To int
+	Policy   Policy
+}
+
+type Config struct {
+	// Rules are checked in order and the first match applies
+	Rules    []Rule
+	Interval time.Duration
+	Location *time.Location
+	// Called for every warning and kick, nil ignores them
+	OnAction func(Action)
+	// Called when a poll or command fails, nil ignores errors
+	OnError func(error)
+}
+
+type Violation string
+
+const (
+	HighPing Violation = "ping"
+	AFK      Violation = "afk"
+)
+
+type Action struct {
+	Time      time.Time
+	ServerID  string
+	Player    string
+	Violation Violation
+	Kicked    bool
+}
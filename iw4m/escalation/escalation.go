@@ -0,0 +1,107 @@
+package escalation
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+// Engine picks the next punishment on a ladder from a player's history and
+// executes it
+type Engine struct {
+	punisher server.Punisher
+	history  History
+	ladders  map[string]Ladder
+}
+
+// Create a new escalation engine
+func New(punisher server.Punisher, history History, ladders []Ladder) (*Engine, error) {
+	e := &Engine{
+		punisher: punisher,
+		history:  history,
+		ladders:  make(map[string]Ladder, len(ladders)),
+	}
+
+	for _, l := range ladders {
+		if len(l.Steps) == 0 {
+			return nil, fmt.Errorf("ladder %q has no steps", l.Offense)
+		}
+		for i, step := range l.Steps {
+			switch step.Action {
+			case Warn, Kick, Ban:
+			case TempBan:
+				if step.Duration < time.Minute {
+					return nil, fmt.Errorf("ladder %q step %d: tempban needs a duration", l.Offense, i+1)
+				}
+			default:
+				return nil, fmt.Errorf("ladder %q step %d: unknown action %q", l.Offense, i+1, step.Action)
+			}
+		}
+		e.ladders[l.Offense] = l
+	}
+
+	return e, nil
+}
+
+// Return the step a client is due for on an offense's ladder and how many
+// earlier offenses were counted. Clients past the end of the ladder stay
+// on its last step
+func (e *Engine) Next(clientID, offense string) (Step, int, error) {
+	ladder, ok := e.ladders[offense]
+	if !ok {
+		return Step{}, 0, fmt.Errorf("no ladder for offense %q", offense)
+	}
+
+	var since time.Time
+	if ladder.Window > 0 {
+		since = time.Now().Add(-ladder.Window)
+	}
+
+	previous, err := e.history.Offenses(clientID, offense, since)
+	if err != nil {
+		return Step{}, 0, err
+	}
+
+	i := min(len(previous), len(ladder.Steps)-1)
+	return ladder.Steps[i], len(previous), nil
+}
+
+// Punish a client for an offense with the next step on its ladder and
+// record it. The client is targeted by its IW4M client id
+func (e *Engine) Punish(serverID, clientID, offense, reason, issuer string) (Record, error) {
+	step, _, err := e.Next(clientID, offense)
+	if err != nil {
+		return Record{}, err
+	}
+
+	target := server.ByClientID(clientID)
+	switch step.Action {
+	case Warn:
+		err = e.punisher.Warn(serverID, target, reason)
+	case Kick:
+		err = e.punisher.Kick(serverID, target, reason)
+	case TempBan:
+		err = e.punisher.TempBan(serverID, target, step.Duration, reason)
+	case Ban:
+		err = e.punisher.Ban(serverID, target, reason)
+	}
+	if err != nil {
+		return Record{}, err
+	}
+
+	record := Record{
+		Time:     time.Now().UTC(),
+		ServerID: serverID,
+		ClientID: clientID,
+		Offense:  offense,
+		Action:   step.Action,
+		Duration: step.Duration,
+		Reason:   reason,
+		Issuer:   issuer,
+	}
+	if err := e.history.Add(record); err != nil {
+		return record, fmt.Errorf("punishment executed but not recorded: %w", err)
+	}
+	return record, nil
+}
@@ -0,0 +1,184 @@
+package escalation
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+// History stores the punishments handed out by the engine. FileHistory only
+// knows what the engine did itself, MergedHistory adds the penalties
+// moderators issued on the webfront
+type History interface {
+	Offenses(clientID, offense string, since time.Time) ([]Record, error)
+	Add(r Record) error
+}
+
+// FileHistory keeps records as JSON lines in a file, one per punishment
+type FileHistory struct {
+	path string
+
+	mu      sync.Mutex
+	records []Record
+}
+
+var _ History = (*FileHistory)(nil)
+
+// Open the history file at path, creating it on the first write
+func OpenFileHistory(path string) (*FileHistory, error) {
+	h := &FileHistory{path: path}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("failed to parse history line %d: %w", line, err)
+		}
+		h.records = append(h.records, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return h, nil
+}
+
+func (h *FileHistory) Offenses(clientID, offense string, since time.Time) ([]Record, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var found []Record
+	for _, r := range h.records {
+		if r.ClientID == clientID && r.Offense == offense && !r.Time.Before(since) {
+			found = append(found, r)
+		}
+	}
+	return found, nil
+}
+
+func (h *FileHistory) Add(r Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	f, err := os.OpenFile(h.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+
+	h.records = append(h.records, r)
+	return nil
+}
+
+// PenaltyReader reads the penalties a client received on the webfront
+type PenaltyReader interface {
+	Penalties(clientID string) ([]server.Penalty, error)
+}
+
+// MergedHistory counts the punishments the engine recorded together with
+// the penalties moderators issued through IW4M-Admin, so manual warnings
+// and bans move a client up the ladder too. New records go to Local
+type MergedHistory struct {
+	Local     History
+	Penalties PenaltyReader
+	// Report whether a webfront penalty counts towards offense. The default
+	// matches the offense name in the penalty reason, ignoring case
+	Classify func(p server.Penalty, offense string) bool
+}
+
+var _ History = (*MergedHistory)(nil)
+
+var penaltyActions = map[string]Action{
+	"Warning": Warn,
+	"Kick":    Kick,
+	"TempBan": TempBan,
+	"Ban":     Ban,
+}
+
+// Return the local records and the matching webfront penalties, oldest
+// first. A penalty the engine issued itself shows up in both, so each
+// local record hides one penalty with the same action and reason.
+// Penalties without a time only count when since is zero
+func (h *MergedHistory) Offenses(clientID, offense string, since time.Time) ([]Record, error) {
+	local, err := h.Local.Offenses(clientID, offense, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	penalties, err := h.Penalties.Penalties(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read penalties: %w", err)
+	}
+
+	classify := h.Classify
+	if classify == nil {
+		classify = func(p server.Penalty, offense string) bool {
+			return strings.Contains(strings.ToLower(p.Reason), strings.ToLower(offense))
+		}
+	}
+
+	var records []Record
+	unmatched := slices.Clone(local)
+	for _, p := range penalties {
+		action, ok := penaltyActions[p.Type]
+		if !ok || !classify(p, offense) {
+			continue
+		}
+
+		i := slices.IndexFunc(unmatched, func(r Record) bool {
+			return r.Action == action && strings.EqualFold(strings.TrimSpace(r.Reason), p.Reason)
+		})
+		if i >= 0 {
+			unmatched = slices.Delete(unmatched, i, i+1)
+			continue
+		}
+
+		if p.Time.IsZero() && !since.IsZero() || !p.Time.IsZero() && p.Time.Before(since) {
+			continue
+		}
+		records = append(records, Record{
+			Time:     p.Time,
+			ClientID: clientID,
+			Offense:  offense,
+			Action:   action,
+			Reason:   p.Reason,
+			Issuer:   p.Punisher,
+		})
+	}
+
+	for _, r := range local {
+		if !r.Time.Before(since) {
+			records = append(records, r)
+		}
+	}
+	slices.SortStableFunc(records, func(a, b Record) int { return a.Time.Compare(b.Time) })
+	return records, nil
+}
+
+func (h *MergedHistory) Add(r Record) error {
+	return h.Local.Add(r)
+}
@@ -0,0 +1,67 @@
+package escalation
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/mock"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+func TestMergedHistory(t *testing.T) {
+	now := time.Now()
+
+	local, err := OpenFileHistory(filepath.Join(t.TempDir(), "history.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := local.Add(Record{Time: now.Add(-time.Hour), ClientID: "7", Offense: "spam", Action: Warn, Reason: "Spam"}); err != nil {
+		t.Fatal(err)
+	}
+
+	backend := mock.NewServer()
+	backend.PenaltyList = map[string][]server.Penalty{"7": {
+		// Issued by the engine, already in the local history
+		{Type: "Warning", Reason: "spam", Time: now.Add(-time.Hour)},
+		// Issued by moderators
+		{Type: "Kick", Reason: "keeps spamming chat", Punisher: "mod", Time: now.Add(-2 * time.Hour)},
+		{Type: "Warning", Reason: "spam", Punisher: "mod", Time: now.Add(-72 * time.Hour)},
+		{Type: "Warning", Reason: "camping", Time: now.Add(-time.Hour)},
+		{Type: "Flag", Reason: "spam", Time: now.Add(-time.Hour)},
+		{Type: "Warning", Reason: "spam, no time shown"},
+	}}
+
+	h := &MergedHistory{Local: local, Penalties: backend}
+
+	tests := []struct {
+		name  string
+		since time.Time
+		want  []Action
+	}{
+		{"everything", time.Time{}, []Action{Warn, Warn, Kick, Warn}},
+		{"last day", now.Add(-24 * time.Hour), []Action{Kick, Warn}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := h.Offenses("7", "spam", tt.since)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []Action
+			for _, r := range records {
+				got = append(got, r.Action)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Offenses = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Offenses = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package escalation
+
+import "time"
+
+type Action string
+
+const (
+	Warn    Action = "warn"
+	Kick    Action = "kick"
+	TempBan Action = "tempban"
+	Ban     Action = "ban"
+)
+
+type Step struct {
+	Action Action `json:"action" yaml:"action"`
+	// Length of the ban for TempBan
+	Duration time.Duration `json:"duration,omitempty" yaml:"duration,omitempty"`
+}
+
+// Ladder is the sequence of punishments for repeated offenses of one kind
+type Ladder struct {
+	Offense string `json:"offense" yaml:"offense"`
+	Steps   []Step `json:"steps" yaml:"steps"`
+	// Only offenses within Window count towards the next step, 0 counts
+	// every recorded offense
+	Window time.Duration `json:"window,omitempty" yaml:"window,omitempty"`
+}
+
+// Record is a punishment that was handed out
+type Record struct {
+	Time     time.Time     `json:"time"`
+	ServerID string        `json:"serverId"`
+	ClientID string        `json:"clientId"`
+	Offense  string        `json:"offense"`
+	Action   Action        `json:"action"`
+	Duration time.Duration `json:"duration,omitempty"`
+	Reason   string        `json:"reason"`
+	Issuer   string        `json:"issuer,omitempty"`
+}
@@ -0,0 +1,58 @@
+package events
+
+import "time"
+
+type Type int
+
+const (
+	PlayerJoin Type = iota
+	PlayerLeave
+	MapChange
+	Chat
+)
+
+func (t Type) String() string {
+	switch t {
+	case PlayerJoin:
+		return "join"
+	case PlayerLeave:
+		return "leave"
+	case MapChange:
+		return "map"
+	case Chat:
+		return "chat"
+	}
+	return "unknown"
+}
+
+type Player struct {
+	Name           string `json:"name"`
+	ClientNumber   int    `json:"clientNumber"`
+	Level          string `json:"level"`
+	Ping           int    `json:"ping"`
+	Score          int    `json:"score"`
+	ConnectionTime int    `json:"connectionTime"`
+}
+
+type Event struct {
+	Type Type      `json:"type"`
+	Time time.Time `json:"time"`
+
+	// For chat these come from the server the sender was connected to when
+	// the line was seen, and are empty when the sender was not found
+	ServerID   string `json:"serverId,omitempty"`
+	ServerName string `json:"serverName,omitempty"`
+
+	// Set for join and leave events, and for chat when the sender was found
+	Player *Player `json:"player,omitempty"`
+
+	// Set for map change events
+	Map         string `json:"map,omitempty"`
+	PreviousMap string `json:"previousMap,omitempty"`
+	GameMode    string `json:"gameMode,omitempty"`
+
+	// Set for chat events. Seq increases by one for every chat line
+	Seq     uint64 `json:"seq,omitempty"`
+	Sender  string `json:"sender,omitempty"`
+	Message string `json:"message,omitempty"`
+}
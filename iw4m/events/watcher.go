@@ -0,0 +1,207 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/chat"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+// Source is the part of the server API the watcher polls
+type Source interface {
+	Status() ([]server.ServerStatus, error)
+	ReadChat() ([]server.Chat, error)
+}
+
+type Handler func(Event)
+
+// Watcher polls Status() and ReadChat() and turns the differences between
+// successive polls into events
+type Watcher struct {
+	source   Source
+	interval time.Duration
+
+	mu       sync.Mutex
+	handlers []Handler
+	// OnError is called when a poll fails, nil ignores errors
+	OnError func(error)
+
+	// State of each server as of its last successful poll. A server is
+	// only diffed once it has a previous state, so the first poll of a
+	// server, including one that appears later, emits no joins
+	players map[string]map[string]Player
+	maps    map[string]string
+	chat    *chat.Reader
+}
+
+// Create a new Watcher polling every interval
+func NewWatcher(source Source, interval time.Duration) *Watcher {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	return &Watcher{
+		source:   source,
+		interval: interval,
+		players:  make(map[string]map[string]Player),
+		maps:     make(map[string]string),
+		chat:     chat.NewReader(source),
+	}
+}
+
+// Register a handler called for every event, in registration order
+func (w *Watcher) Handle(h Handler) {
+	w.mu.Lock()
+	w.handlers = append(w.handlers, h)
+	w.mu.Unlock()
+}
+
+// Poll until ctx is cancelled. The first successful poll of each server
+// only records its state, so players already online are not reported as
+// joining
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.Poll(); err != nil && w.OnError != nil {
+			w.OnError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll once and dispatch the resulting events
+func (w *Watcher) Poll() error {
+	now := time.Now()
+
+	status, statusErr := w.source.Status()
+	lines, chatErr := w.source.ReadChat()
+
+	var events []Event
+	if statusErr == nil {
+		events = append(events, w.diffStatus(status, now)...)
+	}
+	if chatErr == nil {
+		events = append(events, w.diffChat(lines, status, now)...)
+	}
+
+	w.mu.Lock()
+	handlers := append([]Handler(nil), w.handlers...)
+	w.mu.Unlock()
+
+	for _, ev := range events {
+		for _, h := range handlers {
+			h(ev)
+		}
+	}
+
+	if statusErr != nil {
+		return fmt.Errorf("status: %w", statusErr)
+	}
+	if chatErr != nil {
+		return fmt.Errorf("chat: %w", chatErr)
+	}
+	return nil
+}
+
+func (w *Watcher) diffStatus(status []server.ServerStatus, now time.Time) []Event {
+	var events []Event
+
+	for _, s := range status {
+		id := strconv.Itoa(s.ID)
+		base := Event{Time: now, ServerID: id, ServerName: s.Name}
+
+		if previous, ok := w.maps[id]; ok && previous != s.Map.Name {
+			ev := base
+			ev.Type = MapChange
+			ev.Map = s.Map.Name
+			ev.PreviousMap = previous
+			ev.GameMode = s.GameMode
+			events = append(events, ev)
+		}
+		w.maps[id] = s.Map.Name
+
+		current := make(map[string]Player, len(s.Players))
+		for _, p := range s.Players {
+			player := newPlayer(p)
+			current[playerKey(player)] = player
+		}
+
+		previous, known := w.players[id]
+		if known {
+			for key, p := range current {
+				if _, ok := previous[key]; !ok {
+					ev := base
+					ev.Type = PlayerJoin
+					ev.Player = &p
+					events = append(events, ev)
+				}
+			}
+			for key, p := range previous {
+				if _, ok := current[key]; !ok {
+					ev := base
+					ev.Type = PlayerLeave
+					ev.Player = &p
+					events = append(events, ev)
+				}
+			}
+		}
+		w.players[id] = current
+	}
+
+	return events
+}
+
+// The webfront chat does not say which server a line came from, so lines
+// are attributed to the server and player the sender is connected as in
+// the same poll. ServerID stays empty and Player nil when the sender cannot be found, such as for
+// console messages or when the status poll failed
+func (w *Watcher) diffChat(lines []server.Chat, status []server.ServerStatus, now time.Time) []Event {
+	var events []Event
+	for _, m := range w.chat.Advance(lines, now) {
+		ev := Event{
+			Type:    Chat,
+			Time:    m.Time,
+			Seq:     m.Seq,
+			Sender:  m.Sender,
+			Message: m.Message,
+		}
+		if serverID, sender, ok := server.LocatePlayer(status, m.Sender); ok {
+			player := newPlayer(sender)
+			ev.ServerID = serverID
+			ev.Player = &player
+			for _, s := range status {
+				if strconv.Itoa(s.ID) == serverID {
+					ev.ServerName = s.Name
+				}
+			}
+		}
+		events = append(events, ev)
+	}
+	return events
+}
+
+func newPlayer(p server.PlayerStatus) Player {
+	return Player{
+		Name:           p.Name,
+		ClientNumber:   p.ClientNumber,
+		Level:          p.Level,
+		Ping:           p.Ping,
+		Score:          p.Score,
+		ConnectionTime: p.ConnectionTime,
+	}
+}
+
+func playerKey(p Player) string {
+	return strconv.Itoa(p.ClientNumber) + "/" + p.Name
+}
@@ -0,0 +1,88 @@
+package events
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+type fakeSource struct {
+	status []server.ServerStatus
+	err    error
+	chat   []server.Chat
+}
+
+func (f *fakeSource) Status() ([]server.ServerStatus, error) { return f.status, f.err }
+func (f *fakeSource) ReadChat() ([]server.Chat, error)       { return f.chat, nil }
+
+func serverWith(id int, names ...string) server.ServerStatus {
+	st := server.ServerStatus{ID: id, IsOnline: true}
+	for i, name := range names {
+		st.Players = append(st.Players, server.PlayerStatus{Name: name, ClientNumber: i})
+	}
+	return st
+}
+
+func TestWatcherJoins(t *testing.T) {
+	source := &fakeSource{}
+	w := NewWatcher(source, time.Second)
+
+	var joins []string
+	w.Handle(func(ev Event) {
+		if ev.Type == PlayerJoin {
+			joins = append(joins, ev.ServerID+":"+ev.Player.Name)
+		}
+	})
+
+	steps := []struct {
+		name   string
+		status []server.ServerStatus
+		err    error
+		want   int
+	}{
+		{"failed first poll", nil, errors.New("unreachable"), 0},
+		{"first successful poll", []server.ServerStatus{serverWith(1, "a", "b")}, nil, 0},
+		{"player joins", []server.ServerStatus{serverWith(1, "a", "b", "c")}, nil, 1},
+		{"failed poll", nil, errors.New("unreachable"), 1},
+		{"nothing changed", []server.ServerStatus{serverWith(1, "a", "b", "c")}, nil, 1},
+		{"server appears", []server.ServerStatus{serverWith(1, "a", "b", "c"), serverWith(2, "x", "y")}, nil, 1},
+		{"joins on new server", []server.ServerStatus{serverWith(1, "a", "b", "c"), serverWith(2, "x", "y", "z")}, nil, 2},
+	}
+
+	for _, step := range steps {
+		source.status, source.err = step.status, step.err
+		w.Poll()
+		if len(joins) != step.want {
+			t.Fatalf("%s: %d joins %q, want %d", step.name, len(joins), joins, step.want)
+		}
+	}
+}
+
+func TestWatcherAttributesChat(t *testing.T) {
+	source := &fakeSource{status: []server.ServerStatus{serverWith(1, "^1a"), serverWith(2, "b")}}
+	w := NewWatcher(source, time.Second)
+
+	got := make(map[string]string)
+	w.Handle(func(ev Event) {
+		if ev.Type == Chat {
+			got[ev.Message] = ev.ServerID
+		}
+	})
+
+	w.Poll()
+	source.chat = []server.Chat{
+		{Sender: "a", Message: "from one"},
+		{Sender: "b", Message: "from two"},
+		{Sender: "Console", Message: "from nowhere"},
+	}
+	w.Poll()
+
+	want := map[string]string{"from one": "1", "from two": "2", "from nowhere": ""}
+	for message, serverID := range want {
+		if got[message] != serverID {
+			t.Errorf("%q attributed to %q, want %q", message, got[message], serverID)
+		}
+	}
+}
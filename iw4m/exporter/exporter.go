@@ -0,0 +1,189 @@
+package exporter
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/catalog"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "iw4m"
+
+var (
+	serverLabels = []string{"server_id", "server", "game"}
+	playerLabels = []string{"server_id", "server", "player", "client_number"}
+
+	playersDesc = prometheus.NewDesc(
+		namespace+"_server_players", "Players currently connected to the server.", serverLabels, nil)
+	maxPlayersDesc = prometheus.NewDesc(
+		namespace+"_server_max_players", "Player slots on the server.", serverLabels, nil)
+	onlineDesc = prometheus.NewDesc(
+		namespace+"_server_online", "Whether the server is online (1) or not (0).", serverLabels, nil)
+	mapDesc = prometheus.NewDesc(
+		namespace+"_server_map_info", "Current map and gametype of the server, always 1.",
+		append(serverLabels, "map", "map_name", "gametype", "gametype_name"), nil)
+	pingDesc = prometheus.NewDesc(
+		namespace+"_player_ping", "Ping of a connected player in milliseconds.", playerLabels, nil)
+	scoreDesc = prometheus.NewDesc(
+		namespace+"_player_score", "Score of a connected player.", playerLabels, nil)
+	connectedDesc = prometheus.NewDesc(
+		namespace+"_connected_clients", "Clients connected across all servers.", nil, nil)
+	slotsDesc = prometheus.NewDesc(
+		namespace+"_client_slots", "Client slots across all servers.", nil, nil)
+	trackedDesc = prometheus.NewDesc(
+		namespace+"_tracked_clients", "Clients ever tracked by IW4M-Admin.", nil, nil)
+	recentDesc = prometheus.NewDesc(
+		namespace+"_recent_clients", "Clients seen recently.", nil, nil)
+	maxConcurrentDesc = prometheus.NewDesc(
+		namespace+"_max_concurrent_clients", "Highest number of concurrent clients recorded.", nil, nil)
+	durationDesc = prometheus.NewDesc(
+		namespace+"_scrape_duration_seconds", "Duration of the last scrape of the webfront.", nil, nil)
+	errorsDesc = prometheus.NewDesc(
+		namespace+"_scrape_errors_total", "Failed webfront scrapes by endpoint.", []string{"endpoint"}, nil)
+	lastScrapeDesc = prometheus.NewDesc(
+		namespace+"_last_scrape_timestamp_seconds", "Unix time of the last completed scrape.", nil, nil)
+)
+
+// Exporter periodically polls Status() and Info() and exposes the latest
+// results as Prometheus metrics
+type Exporter struct {
+	server   server.Reader
+	interval time.Duration
+
+	mu       sync.RWMutex
+	status   []server.ServerStatus
+	info     *server.ServerInfo
+	duration time.Duration
+	scraped  time.Time
+	errors   map[string]float64
+}
+
+var _ prometheus.Collector = (*Exporter)(nil)
+
+// Create a new Exporter polling the given server every interval
+func NewExporter(s server.Reader, interval time.Duration) *Exporter {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	return &Exporter{
+		server:   s,
+		interval: interval,
+		errors:   map[string]float64{"status": 0, "info": 0},
+	}
+}
+
+// Scrape the webfront until ctx is cancelled
+func (e *Exporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		e.Scrape()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll the webfront once and update the exposed values. Results from a
+// failed endpoint keep their previous value
+func (e *Exporter) Scrape() error {
+	start := time.Now()
+	status, statusErr := e.server.Status()
+	info, infoErr := e.server.Info()
+	duration := time.Since(start)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if statusErr != nil {
+		e.errors["status"]++
+	} else {
+		e.status = status
+	}
+
+	if infoErr != nil {
+		e.errors["info"]++
+	} else {
+		e.info = info
+	}
+
+	e.duration = duration
+	e.scraped = time.Now()
+
+	if statusErr != nil {
+		return statusErr
+	}
+	return infoErr
+}
+
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		playersDesc, maxPlayersDesc, onlineDesc, mapDesc, pingDesc, scoreDesc,
+		connectedDesc, slotsDesc, trackedDesc, recentDesc, maxConcurrentDesc,
+		durationDesc, errorsDesc, lastScrapeDesc,
+	} {
+		ch <- desc
+	}
+}
+
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, s := range e.status {
+		id := strconv.Itoa(s.ID)
+		labels := []string{id, s.Name, s.Game}
+
+		online := 0.0
+		if s.IsOnline {
+			online = 1
+		}
+
+		ch <- prometheus.MustNewConstMetric(playersDesc, prometheus.GaugeValue, float64(s.CurrentPlayers), labels...)
+		ch <- prometheus.MustNewConstMetric(maxPlayersDesc, prometheus.GaugeValue, float64(s.MaxPlayers), labels...)
+		ch <- prometheus.MustNewConstMetric(onlineDesc, prometheus.GaugeValue, online, labels...)
+		ch <- prometheus.MustNewConstMetric(mapDesc, prometheus.GaugeValue, 1, append(labels,
+			s.Map.Name, catalog.MapName(s.Game, s.Map.Name),
+			s.GameMode, catalog.GameTypeName(s.Game, s.GameMode))...)
+
+		for _, p := range s.Players {
+			playerLabels := []string{id, s.Name, p.Name, strconv.Itoa(p.ClientNumber)}
+			ch <- prometheus.MustNewConstMetric(pingDesc, prometheus.GaugeValue, float64(p.Ping), playerLabels...)
+			ch <- prometheus.MustNewConstMetric(scoreDesc, prometheus.GaugeValue, float64(p.Score), playerLabels...)
+		}
+	}
+
+	if e.info != nil {
+		ch <- prometheus.MustNewConstMetric(connectedDesc, prometheus.GaugeValue, float64(e.info.TotalConnectedClients))
+		ch <- prometheus.MustNewConstMetric(slotsDesc, prometheus.GaugeValue, float64(e.info.TotalClientSlots))
+		ch <- prometheus.MustNewConstMetric(trackedDesc, prometheus.GaugeValue, float64(e.info.TotalTrackedClients))
+		ch <- prometheus.MustNewConstMetric(recentDesc, prometheus.GaugeValue, float64(e.info.TotalRecentClients.Value))
+		ch <- prometheus.MustNewConstMetric(maxConcurrentDesc, prometheus.GaugeValue, float64(e.info.MaxConcurrentClients.Value))
+	}
+
+	ch <- prometheus.MustNewConstMetric(durationDesc, prometheus.GaugeValue, e.duration.Seconds())
+	for endpoint, count := range e.errors {
+		ch <- prometheus.MustNewConstMetric(errorsDesc, prometheus.CounterValue, count, endpoint)
+	}
+	if !e.scraped.IsZero() {
+		ch <- prometheus.MustNewConstMetric(lastScrapeDesc, prometheus.GaugeValue, float64(e.scraped.Unix()))
+	}
+}
+
+// Return an HTTP handler serving the exporter's metrics
+func (e *Exporter) Handler() http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(e)
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
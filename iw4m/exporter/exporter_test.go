@@ -0,0 +1,155 @@
+package exporter
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/mock"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func webfront() *mock.Server {
+	st := server.ServerStatus{
+		ID: 1, IsOnline: true, Name: "TDM", Game: "IW4", GameMode: "war",
+		CurrentPlayers: 2, MaxPlayers: 18,
+		Players: []server.PlayerStatus{
+			{Name: "alice", ClientNumber: 0, Ping: 40, Score: 1200},
+			{Name: "bob", ClientNumber: 3, Ping: 90, Score: 300},
+		},
+	}
+	st.Map.Name = "mp_rust"
+	offline := server.ServerStatus{ID: 2, Name: "SND", Game: "IW5"}
+	offline.Map.Name = "mp_dome"
+
+	info := &server.ServerInfo{TotalConnectedClients: 2, TotalClientSlots: 36, TotalTrackedClients: 5000}
+	info.TotalRecentClients.Value = 120
+	info.MaxConcurrentClients.Value = 30
+
+	backend := mock.NewServer()
+	backend.Servers = []server.ServerStatus{st, offline}
+	backend.ServerInfo = info
+	return backend
+}
+
+const serverMetrics = `
+# HELP iw4m_server_players Players currently connected to the server.
+# TYPE iw4m_server_players gauge
+iw4m_server_players{game="IW4",server="TDM",server_id="1"} 2
+iw4m_server_players{game="IW5",server="SND",server_id="2"} 0
+# HELP iw4m_server_online Whether the server is online (1) or not (0).
+# TYPE iw4m_server_online gauge
+iw4m_server_online{game="IW4",server="TDM",server_id="1"} 1
+iw4m_server_online{game="IW5",server="SND",server_id="2"} 0
+# HELP iw4m_server_map_info Current map and gametype of the server, always 1.
+# TYPE iw4m_server_map_info gauge
+iw4m_server_map_info{game="IW4",gametype="war",gametype_name="Team Deathmatch",map="mp_rust",map_name="Rust",server="TDM",server_id="1"} 1
+iw4m_server_map_info{game="IW5",gametype="",gametype_name="",map="mp_dome",map_name="Dome",server="SND",server_id="2"} 1
+# HELP iw4m_player_ping Ping of a connected player in milliseconds.
+# TYPE iw4m_player_ping gauge
+iw4m_player_ping{client_number="0",player="alice",server="TDM",server_id="1"} 40
+iw4m_player_ping{client_number="3",player="bob",server="TDM",server_id="1"} 90
+`
+
+const infoMetrics = `
+# HELP iw4m_client_slots Client slots across all servers.
+# TYPE iw4m_client_slots gauge
+iw4m_client_slots 36
+# HELP iw4m_recent_clients Clients seen recently.
+# TYPE iw4m_recent_clients gauge
+iw4m_recent_clients 120
+# HELP iw4m_max_concurrent_clients Highest number of concurrent clients recorded.
+# TYPE iw4m_max_concurrent_clients gauge
+iw4m_max_concurrent_clients 30
+`
+
+func TestCollect(t *testing.T) {
+	exp := NewExporter(webfront(), 0)
+
+	// Nothing scraped yet, so only the scrape metrics are exposed
+	if n := testutil.CollectAndCount(exp); n != 3 {
+		t.Errorf("collected %d metrics before the first scrape, want 3", n)
+	}
+
+	if err := exp.Scrape(); err != nil {
+		t.Fatal(err)
+	}
+
+	err := testutil.CollectAndCompare(exp, strings.NewReader(serverMetrics),
+		"iw4m_server_players", "iw4m_server_online", "iw4m_server_map_info", "iw4m_player_ping")
+	if err != nil {
+		t.Error(err)
+	}
+	if err := testutil.CollectAndCompare(exp, strings.NewReader(infoMetrics),
+		"iw4m_client_slots", "iw4m_recent_clients", "iw4m_max_concurrent_clients"); err != nil {
+		t.Error(err)
+	}
+	if n := testutil.CollectAndCount(exp, "iw4m_last_scrape_timestamp_seconds"); n != 1 {
+		t.Errorf("%d last scrape timestamps, want 1", n)
+	}
+
+	problems, err := testutil.CollectAndLint(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range problems {
+		t.Errorf("lint: %s: %s", p.Metric, p.Text)
+	}
+}
+
+func TestScrapeFailure(t *testing.T) {
+	backend := webfront()
+	exp := NewExporter(backend, 0)
+	if err := exp.Scrape(); err != nil {
+		t.Fatal(err)
+	}
+
+	backend.Err = errors.New("webfront down")
+	if err := exp.Scrape(); err == nil {
+		t.Fatal("a failed scrape returned no error")
+	}
+	if err := exp.Scrape(); err == nil {
+		t.Fatal("a failed scrape returned no error")
+	}
+
+	errorMetrics := `
+# HELP iw4m_scrape_errors_total Failed webfront scrapes by endpoint.
+# TYPE iw4m_scrape_errors_total counter
+iw4m_scrape_errors_total{endpoint="info"} 2
+iw4m_scrape_errors_total{endpoint="status"} 2
+`
+	if err := testutil.CollectAndCompare(exp, strings.NewReader(errorMetrics), "iw4m_scrape_errors_total"); err != nil {
+		t.Error(err)
+	}
+
+	// The last successful results stay exposed
+	if err := testutil.CollectAndCompare(exp, strings.NewReader(serverMetrics),
+		"iw4m_server_players", "iw4m_server_online", "iw4m_server_map_info", "iw4m_player_ping"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	exp := NewExporter(webfront(), 0)
+	if err := exp.Scrape(); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	exp.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, _ := io.ReadAll(rec.Body)
+	for _, want := range []string{
+		`iw4m_server_max_players{game="IW4",server="TDM",server_id="1"} 18`,
+		`iw4m_player_score{client_number="3",player="bob",server="TDM",server_id="1"} 300`,
+		`iw4m_connected_clients 2`,
+		`iw4m_tracked_clients 5000`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics page is missing %q", want)
+		}
+	}
+}
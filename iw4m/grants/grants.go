@@ -0,0 +1,244 @@
+package grants
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+type Grant struct {
+	ClientID string    `json:"clientId"`
+	Level    string    `json:"level"`
+	Previous string    `json:"previous"`
+	Granted  time.Time `json:"granted"`
+	Expires  time.Time `json:"expires"`
+	Reverted bool      `json:"reverted,omitempty"`
+	// Note is free text such as the donation reference
+	Note string `json:"note,omitempty"`
+}
+
+// Manager grants levels for a limited time and reverts them when they
+// expire. Grants are persisted, so expiry survives restarts
+type Manager struct {
+	levels server.LevelManager
+	path   string
+
+	// Called when reverting an expired grant fails, nil ignores errors
+	OnError func(Grant, error)
+
+	mu     sync.Mutex
+	grants []Grant
+}
+
+// Open the grant store at path and create a manager for it
+func Open(levels server.LevelManager, path string) (*Manager, error) {
+	m := &Manager{levels: levels, path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read grants: %w", err)
+	}
+	if err := json.Unmarshal(data, &m.grants); err != nil {
+		return nil, fmt.Errorf("failed to parse grants: %w", err)
+	}
+	return m, nil
+}
+
+// Grant a level to a client for duration. Granting again while a grant is
+// active replaces it but keeps the level the client had before the first.
+// Levels at or below the client's own are refused, so a grant never
+// demotes anyone. When the change cannot be verified the grant is still
+// stored and the error returned with it
+func (m *Manager) Grant(clientID, level string, duration time.Duration, note string) (Grant, error) {
+	if duration <= 0 {
+		return Grant{}, fmt.Errorf("grant duration must be positive")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	previous := ""
+	active := m.active(clientID)
+	if active != nil {
+		previous = active.Previous
+	} else {
+		current, err := m.levels.Level(clientID)
+		if err != nil {
+			return Grant{}, err
+		}
+		previous = current
+	}
+
+	if server.LevelRank(previous) >= server.LevelRank(level) {
+		return Grant{}, fmt.Errorf("client %s is already %s, granting %s would not raise them", clientID, previous, level)
+	}
+
+	// When the level was submitted but could not be verified it may
+	// already be raised, so the grant is kept to make sure it gets
+	// reverted. Any other failure means nothing changed
+	change, setErr := m.levels.SetLevel(clientID, level)
+	if setErr != nil && (change == nil || !errors.Is(setErr, server.ErrUnverified)) {
+		return Grant{}, setErr
+	}
+
+	now := time.Now().UTC()
+	grant := Grant{
+		ClientID: clientID,
+		Level:    change.Requested,
+		Previous: previous,
+		Granted:  now,
+		Expires:  now.Add(duration),
+		Note:     note,
+	}
+
+	if active != nil {
+		*active = grant
+	} else {
+		m.grants = append(m.grants, grant)
+	}
+	return grant, errors.Join(setErr, m.save())
+}
+
+// Revert a client's active grant now
+func (m *Manager) Revoke(clientID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	g := m.active(clientID)
+	if g == nil {
+		return fmt.Errorf("client %s has no active grant", clientID)
+	}
+	if err := m.revert(g); err != nil {
+		return err
+	}
+	return m.save()
+}
+
+// Return the grants that have not been reverted
+func (m *Manager) Active() []Grant {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var active []Grant
+	for _, g := range m.grants {
+		if !g.Reverted {
+			active = append(active, g)
+		}
+	}
+	return active
+}
+
+// Revert every grant that expired before now
+func (m *Manager) Expire(now time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var errs []error
+	changed := false
+	for i := range m.grants {
+		g := &m.grants[i]
+		if g.Reverted || now.Before(g.Expires) {
+			continue
+		}
+
+		if err := m.revert(g); err != nil {
+			errs = append(errs, err)
+			if m.OnError != nil {
+				m.OnError(*g, err)
+			}
+			continue
+		}
+		changed = true
+	}
+
+	if changed {
+		errs = append(errs, m.save())
+	}
+	return errors.Join(errs...)
+}
+
+// Expire grants now and then every interval until ctx is cancelled, so
+// grants that ran out while the process was down are reverted at startup
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		m.Expire(time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Restore the previous level, unless someone changed the level by hand in
+// the meantime. Must be called with m.mu held
+func (m *Manager) revert(g *Grant) error {
+	current, err := m.levels.Level(g.ClientID)
+	if err != nil {
+		return err
+	}
+
+	if server.SameLevel(current, g.Level) {
+		if _, err := m.levels.SetLevel(g.ClientID, g.Previous); err != nil {
+			return err
+		}
+	}
+
+	g.Reverted = true
+	return nil
+}
+
+// Must be called with m.mu held
+func (m *Manager) active(clientID string) *Grant {
+	for i := range m.grants {
+		if m.grants[i].ClientID == clientID && !m.grants[i].Reverted {
+			return &m.grants[i]
+		}
+	}
+	return nil
+}
+
+// Write the store to a temporary file and rename it into place, so a crash
+// never leaves a truncated file. Must be called with m.mu held
+func (m *Manager) save() error {
+	data, err := json.MarshalIndent(m.grants, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(m.path), filepath.Base(m.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to save grants: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save grants: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save grants: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), m.path); err != nil {
+		return fmt.Errorf("failed to save grants: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package grants
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/mock"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+// unverified applies level changes but reports that verifying them failed
+type unverified struct {
+	*mock.Server
+}
+
+func (u unverified) SetLevel(clientID, level string) (*server.LevelChange, error) {
+	change, err := u.Server.SetLevel(clientID, level)
+	if err != nil {
+		return nil, err
+	}
+	change.Verified = false
+	return change, fmt.Errorf("failed to verify level change: %w", server.ErrUnverified)
+}
+
+// rejected fails every level change the way a refused form submit does
+type rejected struct {
+	*mock.Server
+}
+
+func (r rejected) SetLevel(clientID, level string) (*server.LevelChange, error) {
+	return nil, errors.New("/Action/Edit returned 403 Forbidden")
+}
+
+func newBackend() *mock.Server {
+	backend := mock.NewServer()
+	backend.StockRoleList = server.StockLevels
+	backend.Levels["1"] = "User"
+	backend.Levels["2"] = "Administrator"
+	return backend
+}
+
+func TestGrantRefusesDemotion(t *testing.T) {
+	backend := newBackend()
+	m, err := Open(backend, filepath.Join(t.TempDir(), "grants.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		clientID string
+		level    string
+		ok       bool
+	}{
+		{"2", "Trusted", false},
+		{"2", "Administrator", false},
+		{"1", "User", false},
+		{"1", "Trusted", true},
+	}
+
+	for _, tt := range tests {
+		_, err := m.Grant(tt.clientID, tt.level, time.Hour, "")
+		if (err == nil) != tt.ok {
+			t.Errorf("Grant(%s, %s) error = %v, want ok %v", tt.clientID, tt.level, err, tt.ok)
+		}
+	}
+
+	if got := backend.Levels["2"]; got != "Administrator" {
+		t.Errorf("level of client 2 = %s, want Administrator", got)
+	}
+	if got := len(m.Active()); got != 1 {
+		t.Errorf("%d active grants, want 1", got)
+	}
+}
+
+func TestGrantKeptWhenUnverified(t *testing.T) {
+	backend := newBackend()
+	path := filepath.Join(t.TempDir(), "grants.json")
+	m, err := Open(unverified{backend}, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Grant("1", "Trusted", time.Hour, ""); err == nil {
+		t.Fatal("Grant succeeded, want the verification error")
+	}
+
+	reopened, err := Open(backend, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(reopened.Active()); got != 1 {
+		t.Fatalf("%d grants persisted, want 1", got)
+	}
+
+	if err := reopened.Expire(time.Now().Add(2 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if got := backend.Levels["1"]; got != "User" {
+		t.Errorf("level after expiry = %s, want User", got)
+	}
+}
+
+func TestGrantNotKeptWhenSubmitFails(t *testing.T) {
+	backend := newBackend()
+	path := filepath.Join(t.TempDir(), "grants.json")
+	m, err := Open(rejected{backend}, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Grant("1", "Trusted", time.Hour, ""); err == nil {
+		t.Fatal("Grant succeeded, want the submit error")
+	}
+	if got := len(m.Active()); got != 0 {
+		t.Errorf("%d active grants, want 0", got)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("grant store written after a failed submit: %v", err)
+	}
+	if got := backend.Levels["1"]; got != "User" {
+		t.Errorf("level = %s, want User", got)
+	}
+}
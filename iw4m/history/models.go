@@ -0,0 +1,74 @@
+package history
+
+import (
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/events"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+type Kind string
+
+const (
+	Snapshots Kind = "snapshots"
+	ChatLines Kind = "chat"
+	Audit     Kind = "audit"
+	Reports   Kind = "reports"
+	Events    Kind = "events"
+	Sessions  Kind = "sessions"
+)
+
+type Snapshot struct {
+	Time     time.Time           `json:"time"`
+	ServerID string              `json:"serverId"`
+	Status   server.ServerStatus `json:"status"`
+}
+
+type ChatLine struct {
+	Time time.Time `json:"time"`
+	// Empty when the sender was not found on any server, such lines never
+	// match a Query.ServerID
+	ServerID string `json:"serverId,omitempty"`
+	Sender   string `json:"sender"`
+	Message  string `json:"message"`
+}
+
+type AuditEntry struct {
+	Time time.Time       `json:"time"`
+	Log  server.AuditLog `json:"log"`
+}
+
+type ReportEntry struct {
+	Time   time.Time     `json:"time"`
+	Report server.Report `json:"report"`
+}
+
+type EventEntry struct {
+	Time  time.Time    `json:"time"`
+	Event events.Event `json:"event"`
+}
+
+// Session is one stay of a player on a server, from the first snapshot
+// that listed them to the last
+type Session struct {
+	ServerID string    `json:"serverId"`
+	Player   string    `json:"player"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+}
+
+func (s Session) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// Query selects records by time range, server and player. Zero values
+// match everything
+type Query struct {
+	From     time.Time
+	To       time.Time
+	ServerID string
+	// Player matches names without color codes, ignoring case
+	Player string
+	// Limit caps the number of results, 0 returns all
+	Limit int
+}
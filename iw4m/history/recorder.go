@@ -0,0 +1,123 @@
+package history
+
+import (
+	"context"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/events"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+// Source is the part of the server API the recorder polls
+type Source interface {
+	Status() ([]server.ServerStatus, error)
+	AuditLogs(count int) ([]server.AuditLog, error)
+	Reports() ([]server.Report, error)
+}
+
+// Recorder polls the webfront into a Store. Audit entries and reports are
+// shown again on every poll, so the recorder only stores ones it has not
+// seen before. After a restart the entries still on the page are stored
+// again. Chat and events are recorded through Handle
+type Recorder struct {
+	store  *Store
+	source Source
+
+	// Called when polling or writing fails, nil ignores errors
+	OnError func(error)
+
+	audit   map[server.AuditLog]bool
+	reports map[server.Report]bool
+}
+
+// Create a new recorder writing to store
+func NewRecorder(store *Store, source Source) *Recorder {
+	return &Recorder{
+		store:   store,
+		source:  source,
+		audit:   make(map[server.AuditLog]bool),
+		reports: make(map[server.Report]bool),
+	}
+}
+
+// Poll every interval until ctx is cancelled
+func (r *Recorder) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		r.Poll(time.Now().UTC())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Record a status snapshot and any new audit entries and reports
+func (r *Recorder) Poll(now time.Time) {
+	if status, err := r.source.Status(); err != nil {
+		r.fail(err)
+	} else {
+		r.fail(r.store.RecordStatus(now, status))
+	}
+
+	if logs, err := r.source.AuditLogs(100); err != nil {
+		r.fail(err)
+	} else {
+		seen := make(map[server.AuditLog]bool, len(logs))
+		var fresh []AuditEntry
+		for _, l := range logs {
+			seen[l] = true
+			if !r.audit[l] {
+				fresh = append(fresh, AuditEntry{Time: now, Log: l})
+			}
+		}
+		// Only remember what is still on the page, so memory stays bounded
+		r.audit = seen
+		r.fail(r.store.RecordAudit(fresh...))
+	}
+
+	if reports, err := r.source.Reports(); err != nil {
+		r.fail(err)
+	} else {
+		seen := make(map[server.Report]bool, len(reports))
+		var fresh []ReportEntry
+		for _, rep := range reports {
+			seen[rep] = true
+			if !r.reports[rep] {
+				fresh = append(fresh, ReportEntry{Time: now, Report: rep})
+			}
+		}
+		r.reports = seen
+		r.fail(r.store.RecordReports(fresh...))
+	}
+}
+
+// Record events from an events.Watcher. Chat events are also stored as
+// chat lines
+func (r *Recorder) Handle(ev events.Event) {
+	t := ev.Time.UTC()
+	r.fail(r.store.RecordEvents(EventEntry{Time: t, Event: ev}))
+
+	if ev.Type == events.Chat {
+		r.fail(r.store.RecordChat(ChatLine{
+			Time:     t,
+			ServerID: ev.ServerID,
+			Sender:   ev.Sender,
+			Message:  ev.Message,
+		}))
+	}
+}
+
+func (r *Recorder) fail(err error) {
+	if err != nil && r.OnError != nil {
+		r.OnError(err)
+	}
+}
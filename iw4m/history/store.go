@@ -0,0 +1,253 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+// Layout of the daily file names
+const dayLayout = "2006-01-02"
+
+// Store keeps records in append-only JSON lines files, one directory per
+// kind and one file per UTC day, so time range queries only open the days
+// they cover
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// Open a store rooted at dir, creating it if needed
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Record one snapshot per server from a Status() result
+func (s *Store) RecordStatus(t time.Time, status []server.ServerStatus) error {
+	records := make([]any, 0, len(status))
+	for _, st := range status {
+		records = append(records, Snapshot{Time: t, ServerID: strconv.Itoa(st.ID), Status: st})
+	}
+	return s.append(Snapshots, t, records...)
+}
+
+func (s *Store) RecordChat(lines ...ChatLine) error {
+	return appendEach(s, ChatLines, lines, func(l ChatLine) time.Time { return l.Time })
+}
+
+func (s *Store) RecordAudit(entries ...AuditEntry) error {
+	return appendEach(s, Audit, entries, func(e AuditEntry) time.Time { return e.Time })
+}
+
+func (s *Store) RecordReports(entries ...ReportEntry) error {
+	return appendEach(s, Reports, entries, func(e ReportEntry) time.Time { return e.Time })
+}
+
+func (s *Store) RecordEvents(entries ...EventEntry) error {
+	return appendEach(s, Events, entries, func(e EventEntry) time.Time { return e.Time })
+}
+
+// Sessions are filed under the day they started
+func (s *Store) RecordSessions(sessions ...Session) error {
+	return appendEach(s, Sessions, sessions, func(ss Session) time.Time { return ss.Start })
+}
+
+func (s *Store) Snapshots(q Query) ([]Snapshot, error) {
+	return query(s, Snapshots, q, func(r Snapshot) (time.Time, bool) {
+		return r.Time, matchServer(q, r.ServerID) && (q.Player == "" ||
+			slices.ContainsFunc(r.Status.Players, func(p server.PlayerStatus) bool {
+				return matchPlayer(q, p.Name)
+			}))
+	})
+}
+
+func (s *Store) Chat(q Query) ([]ChatLine, error) {
+	return query(s, ChatLines, q, func(r ChatLine) (time.Time, bool) {
+		return r.Time, matchServer(q, r.ServerID) && matchPlayer(q, r.Sender)
+	})
+}
+
+// Search chat for a phrase, ignoring case, color codes and repeated
+// whitespace, within the lines selected by q. An empty phrase matches
+// every line
+func (s *Store) SearchChat(q Query, phrase string) ([]ChatLine, error) {
+	phrase = normalizeText(phrase)
+	return query(s, ChatLines, q, func(r ChatLine) (time.Time, bool) {
+		return r.Time, matchServer(q, r.ServerID) && matchPlayer(q, r.Sender) &&
+			strings.Contains(normalizeText(r.Message), phrase)
+	})
+}
+
+func (s *Store) Audit(q Query) ([]AuditEntry, error) {
+	return query(s, Audit, q, func(r AuditEntry) (time.Time, bool) {
+		return r.Time, matchPlayer(q, r.Log.Origin) || matchPlayer(q, r.Log.Target)
+	})
+}
+
+func (s *Store) Reports(q Query) ([]ReportEntry, error) {
+	return query(s, Reports, q, func(r ReportEntry) (time.Time, bool) {
+		return r.Time, matchPlayer(q, r.Report.Origin) || matchPlayer(q, r.Report.Target)
+	})
+}
+
+func (s *Store) Events(q Query) ([]EventEntry, error) {
+	return query(s, Events, q, func(r EventEntry) (time.Time, bool) {
+		name := r.Event.Sender
+		if r.Event.Player != nil {
+			name = r.Event.Player.Name
+		}
+		return r.Time, matchServer(q, r.Event.ServerID) && matchPlayer(q, name)
+	})
+}
+
+// Sessions that started within the query's time range
+func (s *Store) Sessions(q Query) ([]Session, error) {
+	return query(s, Sessions, q, func(r Session) (time.Time, bool) {
+		return r.Start, matchServer(q, r.ServerID) && matchPlayer(q, r.Player)
+	})
+}
+
+func appendEach[T any](s *Store, kind Kind, items []T, at func(T) time.Time) error {
+	var errs []error
+	for _, item := range items {
+		errs = append(errs, s.append(kind, at(item), item))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Store) append(kind Kind, t time.Time, records ...any) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	var buf []byte
+	for _, r := range records {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		buf = append(append(buf, data...), '\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir := filepath.Join(s.dir, string(kind))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s directory: %w", kind, err)
+	}
+
+	path := filepath.Join(dir, t.UTC().Format(dayLayout)+".jsonl")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(buf); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Read the records of a kind in time order, keeping those match accepts
+func query[T any](s *Store, kind Kind, q Query, match func(T) (time.Time, bool)) ([]T, error) {
+	days, err := s.days(kind, q)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []T
+	for _, day := range days {
+		f, err := os.Open(day)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", day, err)
+		}
+
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			var r T
+			if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+				continue // a line cut short by a crash
+			}
+
+			t, ok := match(r)
+			if !ok || !q.From.IsZero() && t.Before(q.From) || !q.To.IsZero() && !t.Before(q.To) {
+				continue
+			}
+
+			results = append(results, r)
+			if q.Limit > 0 && len(results) >= q.Limit {
+				f.Close()
+				return results, nil
+			}
+		}
+
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", day, err)
+		}
+	}
+	return results, nil
+}
+
+// List the daily files of a kind that overlap the query's time range
+func (s *Store) days(kind Kind, q Query) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(s.dir, string(kind)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var days []string
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".jsonl")
+		if !ok {
+			continue
+		}
+		day, err := time.Parse(dayLayout, name)
+		if err != nil {
+			continue
+		}
+
+		if !q.From.IsZero() && day.Add(24*time.Hour).Before(q.From) {
+			continue
+		}
+		if !q.To.IsZero() && !day.Before(q.To) {
+			continue
+		}
+		days = append(days, filepath.Join(s.dir, string(kind), e.Name()))
+	}
+
+	slices.Sort(days)
+	return days, nil
+}
+
+func matchServer(q Query, serverID string) bool {
+	return q.ServerID == "" || q.ServerID == serverID
+}
+
+func matchPlayer(q Query, name string) bool {
+	return q.Player == "" || strings.EqualFold(server.StripColors(name), server.StripColors(q.Player))
+}
+
+func normalizeText(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(server.StripColors(s))), " ")
+}
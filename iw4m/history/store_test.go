@@ -0,0 +1,52 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSearchChat(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC)
+	lines := []ChatLine{
+		{Time: start, ServerID: "1", Sender: "^1Alice", Message: "anyone up for a ^2Scrim tonight"},
+		{Time: start.Add(30 * time.Minute), ServerID: "2", Sender: "Bob", Message: "scrim   TONIGHT?"},
+		{Time: start.Add(90 * time.Minute), ServerID: "1", Sender: "Bob", Message: "gg everyone"},
+		{Time: start.Add(2 * time.Hour), Sender: "Console", Message: "scrim tonight at 9"},
+	}
+	if err := store.RecordChat(lines...); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		q      Query
+		phrase string
+		want   int
+	}{
+		{"phrase ignores case, colors and spacing", Query{}, "scrim tonight", 3},
+		{"empty phrase", Query{}, "", 4},
+		{"by server", Query{ServerID: "1"}, "scrim", 1},
+		{"by player", Query{Player: "alice"}, "", 1},
+		{"by time across days", Query{From: start.Add(time.Hour)}, "", 2},
+		{"time range", Query{From: start.Add(10 * time.Minute), To: start.Add(time.Hour)}, "", 1},
+		{"limit", Query{Limit: 2}, "", 2},
+		{"no match", Query{}, "ban appeal", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.SearchChat(tt.q, tt.phrase)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("SearchChat = %d lines %v, want %d", len(got), got, tt.want)
+			}
+		})
+	}
+}
@@ -2,7 +2,11 @@ package iw4m
 
 import (
 	"fmt"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
 type IW4MWrapper struct {
@@ -10,6 +14,15 @@ type IW4MWrapper struct {
 	ServerID string
 	Cookie   string
 	Client   *http.Client
+
+	// Logger receives request logs and parse warnings, nil disables logging.
+	// Each request is logged when its headers arrive and again with the
+	// number of bytes read once the caller closes the body
+	Logger *slog.Logger
+	// Debug dumps sanitized request and response bodies at debug level
+	Debug bool
+	// Metrics receives request and parse instrumentation, nil disables it
+	Metrics Metrics
 }
 
 func (iw4m *IW4MWrapper) DoRequest(endpoint string) (*http.Response, error) {
@@ -19,15 +32,57 @@ func (iw4m *IW4MWrapper) DoRequest(endpoint string) (*http.Response, error) {
 		return nil, err
 	}
 
+	return iw4m.do(req, endpoint, nil)
+}
+
+// Submit a form to the webfront
+func (iw4m *IW4MWrapper) DoPostRequest(endpoint string, form url.Values) (*http.Response, error) {
+	target := fmt.Sprintf("%s%s", iw4m.BaseURL, endpoint)
+	body := form.Encode()
+	req, err := http.NewRequest("POST", target, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return iw4m.do(req, endpoint, []byte(body))
+}
+
+func (iw4m *IW4MWrapper) do(req *http.Request, endpoint string, body []byte) (*http.Response, error) {
 	req.Header.Set("Cookie", iw4m.Cookie)
+	if iw4m.Logger != nil && iw4m.Debug {
+		iw4m.dumpRequest(req, endpoint, body)
+	}
+
+	start := time.Now()
 	res, err := iw4m.Client.Do(req)
 	if err != nil {
+		iw4m.Log().Error("request failed",
+			"method", req.Method,
+			"endpoint", endpoint,
+			"latency", time.Since(start),
+			"error", err,
+		)
+		if iw4m.Metrics != nil {
+			iw4m.Metrics.ObserveRequest(EndpointLabel(endpoint), 0, time.Since(start), 0)
+		}
 		return nil, err
 	}
 
+	if iw4m.Logger != nil || iw4m.Metrics != nil {
+		iw4m.traceResponse(res, endpoint, time.Since(start))
+	}
 	return res, nil
 }
 
+// Return the configured logger, or one that discards everything
+func (iw4m *IW4MWrapper) Log() *slog.Logger {
+	if iw4m.Logger == nil {
+		return discardLogger
+	}
+	return iw4m.Logger
+}
+
 // Create a new instance of the iw4m wrapper
 func NewWrapper(baseUrl string, serverID string, cookie string) *IW4MWrapper {
 	return &IW4MWrapper{
@@ -0,0 +1,123 @@
+package iw4m
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Longest body dumped in debug mode
+const maxDumpSize = 4096
+
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// trackedBody logs and measures the request once the caller is done reading
+// the body, so both include the number of bytes received. The request itself
+// is logged when the headers arrive, so a body that is never closed still
+// leaves a trace
+type trackedBody struct {
+	io.ReadCloser
+	logger   *slog.Logger
+	metrics  Metrics
+	method   string
+	endpoint string
+	status   int
+	latency  time.Duration
+	bytes    int64
+	done     bool
+}
+
+func (b *trackedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.bytes += int64(n)
+	return n, err
+}
+
+func (b *trackedBody) Close() error {
+	err := b.ReadCloser.Close()
+	if b.done {
+		return err
+	}
+	b.done = true
+
+	if b.logger != nil {
+		b.logger.Info("response read",
+			"method", b.method,
+			"endpoint", b.endpoint,
+			"status", b.status,
+			"bytes", b.bytes,
+		)
+	}
+	if b.metrics != nil {
+		b.metrics.ObserveRequest(EndpointLabel(b.endpoint), b.status, b.latency, b.bytes)
+	}
+	return err
+}
+
+// Log a response as soon as its headers arrive. The wrapper has no response
+// cache and never retries, so every response is one round trip and the logs
+// carry neither a cache status nor a retry count
+func (iw4m *IW4MWrapper) traceResponse(res *http.Response, endpoint string, latency time.Duration) {
+	iw4m.Log().Info("request",
+		"method", res.Request.Method,
+		"endpoint", endpoint,
+		"status", res.StatusCode,
+		"latency", latency,
+	)
+
+	if iw4m.Logger != nil && iw4m.Debug {
+		body, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			iw4m.Logger.Warn("failed to read response body for dump", "endpoint", endpoint, "error", err)
+		}
+		iw4m.Logger.Debug("response",
+			"endpoint", endpoint,
+			"status", res.StatusCode,
+			"header", sanitizeHeader(res.Header),
+			"body", sanitizeBody(body),
+		)
+		res.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	res.Body = &trackedBody{
+		ReadCloser: res.Body,
+		logger:     iw4m.Logger,
+		metrics:    iw4m.Metrics,
+		method:     res.Request.Method,
+		endpoint:   endpoint,
+		status:     res.StatusCode,
+		latency:    latency,
+	}
+}
+
+func (iw4m *IW4MWrapper) dumpRequest(req *http.Request, endpoint string, body []byte) {
+	iw4m.Logger.Debug("request",
+		"method", req.Method,
+		"endpoint", endpoint,
+		"header", sanitizeHeader(req.Header),
+		"body", sanitizeBody(body),
+	)
+}
+
+func sanitizeHeader(header http.Header) map[string]string {
+	sanitized := make(map[string]string, len(header))
+	for key := range header {
+		switch http.CanonicalHeaderKey(key) {
+		case "Cookie", "Set-Cookie", "Authorization":
+			sanitized[key] = Redacted
+		default:
+			sanitized[key] = RedactIPs(header.Get(key))
+		}
+	}
+	return sanitized
+}
+
+func sanitizeBody(body []byte) string {
+	if len(body) > maxDumpSize {
+		return RedactIPs(string(body[:maxDumpSize])) + "...(truncated)"
+	}
+	return RedactIPs(string(body))
+}
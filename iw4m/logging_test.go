@@ -0,0 +1,92 @@
+package iw4m
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// Return the records logged so far, one map per line
+func records(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var recs []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatal(err)
+		}
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+func newLoggedWrapper(buf *bytes.Buffer, rt http.RoundTripper) *IW4MWrapper {
+	wrapper := NewWrapper("http://webfront", "1", "cookie")
+	wrapper.Client.Transport = rt
+	wrapper.Logger = slog.New(slog.NewJSONHandler(buf, nil))
+	return wrapper
+}
+
+func TestRequestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	wrapper := newLoggedWrapper(&buf, roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader("hello world")),
+			Request:    req,
+		}, nil
+	}))
+
+	res, err := wrapper.DoRequest("/Home/Help")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Logged on header receipt, before the body is read or closed
+	recs := records(t, &buf)
+	if len(recs) != 1 || recs[0]["msg"] != "request" || recs[0]["status"] != 200.0 || recs[0]["endpoint"] != "/Home/Help" {
+		t.Fatalf("logged %v before the body was closed, want one request record", recs)
+	}
+	if _, ok := recs[0]["latency"]; !ok {
+		t.Error("request record has no latency")
+	}
+
+	if _, err := io.ReadAll(res.Body); err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	res.Body.Close()
+
+	recs = records(t, &buf)
+	if len(recs) != 2 || recs[1]["msg"] != "response read" || recs[1]["bytes"] != 11.0 {
+		t.Errorf("logged %v after closing the body twice, want one response read record with 11 bytes", recs)
+	}
+}
+
+func TestRequestLoggingFailure(t *testing.T) {
+	var buf bytes.Buffer
+	wrapper := newLoggedWrapper(&buf, roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	}))
+
+	if _, err := wrapper.DoRequest("/Home/Help"); err == nil {
+		t.Fatal("request succeeded")
+	}
+
+	recs := records(t, &buf)
+	if len(recs) != 1 || recs[0]["msg"] != "request failed" || recs[0]["level"] != "ERROR" {
+		t.Errorf("logged %v, want one request failed error", recs)
+	}
+}
@@ -0,0 +1,43 @@
+package iw4m
+
+import (
+	"strings"
+	"time"
+)
+
+// Metrics receives instrumentation from the wrapper. Endpoints are passed
+// without their query string and with numeric path segments replaced by
+// ":id", so they are safe to use as metric labels
+type Metrics interface {
+	// Called once the response body is closed. Status is 0 when the request
+	// failed before a response was received
+	ObserveRequest(endpoint string, status int, latency time.Duration, size int64)
+	// Called after an HTML response has been parsed
+	ObserveParse(endpoint string, duration time.Duration)
+}
+
+// Reduce an endpoint to a low cardinality label
+func EndpointLabel(endpoint string) string {
+	if i := strings.IndexByte(endpoint, '?'); i >= 0 {
+		endpoint = endpoint[:i]
+	}
+	segments := strings.Split(endpoint, "/")
+	for i, seg := range segments {
+		if seg != "" && strings.Trim(seg, "0123456789") == "" {
+			segments[i] = ":id"
+		}
+	}
+	endpoint = strings.Join(segments, "/")
+	if endpoint == "" {
+		return "/"
+	}
+	return endpoint
+}
+
+// Report how long parsing the response for endpoint took
+func (iw4m *IW4MWrapper) ObserveParse(endpoint string, duration time.Duration) {
+	if iw4m.Metrics != nil {
+		iw4m.Metrics.ObserveParse(EndpointLabel(endpoint), duration)
+	}
+	iw4m.Log().Debug("parsed response", "endpoint", endpoint, "duration", duration)
+}
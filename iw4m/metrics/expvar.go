@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"expvar"
+	"strconv"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m"
+)
+
+// Expvar records wrapper instrumentation as expvar counters. Each map is
+// keyed by endpoint, so averages can be derived from the totals
+type Expvar struct {
+	requests  *expvar.Map
+	responses *expvar.Map
+	latency   *expvar.Map
+	bytes     *expvar.Map
+	parses    *expvar.Map
+	parseTime *expvar.Map
+}
+
+var _ iw4m.Metrics = (*Expvar)(nil)
+
+// Create a new expvar recorder published under name. Like expvar.Publish,
+// it panics if name is already in use
+func NewExpvar(name string) *Expvar {
+	root := expvar.NewMap(name)
+
+	e := &Expvar{
+		requests:  new(expvar.Map).Init(),
+		responses: new(expvar.Map).Init(),
+		latency:   new(expvar.Map).Init(),
+		bytes:     new(expvar.Map).Init(),
+		parses:    new(expvar.Map).Init(),
+		parseTime: new(expvar.Map).Init(),
+	}
+
+	root.Set("requests", e.requests)
+	root.Set("responses", e.responses)
+	root.Set("latency_seconds_total", e.latency)
+	root.Set("response_bytes_total", e.bytes)
+	root.Set("parses", e.parses)
+	root.Set("parse_seconds_total", e.parseTime)
+	return e
+}
+
+func (e *Expvar) ObserveRequest(endpoint string, status int, latency time.Duration, size int64) {
+	e.requests.Add(endpoint, 1)
+	e.responses.Add(endpoint+" "+strconv.Itoa(status), 1)
+	e.latency.AddFloat(endpoint, latency.Seconds())
+	e.bytes.Add(endpoint, size)
+}
+
+func (e *Expvar) ObserveParse(endpoint string, duration time.Duration) {
+	e.parses.Add(endpoint, 1)
+	e.parseTime.AddFloat(endpoint, duration.Seconds())
+}
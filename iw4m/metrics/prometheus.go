@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Prometheus records wrapper instrumentation as Prometheus metrics
+type Prometheus struct {
+	latency   *prometheus.HistogramVec
+	responses *prometheus.CounterVec
+	size      *prometheus.HistogramVec
+	parse     *prometheus.HistogramVec
+}
+
+var _ iw4m.Metrics = (*Prometheus)(nil)
+
+// Create a new Prometheus recorder registered with reg. A nil reg uses the
+// default registerer
+func NewPrometheus(reg prometheus.Registerer) *Prometheus {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+
+	p := &Prometheus{
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "iw4m",
+			Subsystem: "client",
+			Name:      "request_duration_seconds",
+			Help:      "Time until the webfront returned response headers.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint"}),
+		responses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "iw4m",
+			Subsystem: "client",
+			Name:      "responses_total",
+			Help:      "Webfront responses by status code, 0 for transport errors.",
+		}, []string{"endpoint", "code"}),
+		size: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "iw4m",
+			Subsystem: "client",
+			Name:      "response_size_bytes",
+			Help:      "Size of webfront response bodies.",
+			Buckets:   prometheus.ExponentialBuckets(256, 4, 8),
+		}, []string{"endpoint"}),
+		parse: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "iw4m",
+			Subsystem: "client",
+			Name:      "parse_duration_seconds",
+			Help:      "Time spent parsing webfront HTML.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 12),
+		}, []string{"endpoint"}),
+	}
+
+	reg.MustRegister(p.latency, p.responses, p.size, p.parse)
+	return p
+}
+
+func (p *Prometheus) ObserveRequest(endpoint string, status int, latency time.Duration, size int64) {
+	p.responses.WithLabelValues(endpoint, strconv.Itoa(status)).Inc()
+	if status == 0 {
+		return
+	}
+	p.latency.WithLabelValues(endpoint).Observe(latency.Seconds())
+	p.size.WithLabelValues(endpoint).Observe(float64(size))
+}
+
+func (p *Prometheus) ObserveParse(endpoint string, duration time.Duration) {
+	p.parse.WithLabelValues(endpoint).Observe(duration.Seconds())
+}
@@ -0,0 +1,27 @@
+package iw4m
+
+import "testing"
+
+func TestEndpointLabel(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+	}{
+		{"", "/"},
+		{"/", "/"},
+		{"/Home/Help", "/Home/Help"},
+		{"/Client/Profile/123", "/Client/Profile/:id"},
+		{"/Client/Profile/123/", "/Client/Profile/:id/"},
+		{"/a/1/2", "/a/:id/:id"},
+		{"/a/1/2/3/b", "/a/:id/:id/:id/b"},
+		{"/Stats/GetTopPlayersAsync?offset=0&count=20", "/Stats/GetTopPlayersAsync"},
+		{"/Action/editForm/?id=2&meta=", "/Action/editForm/"},
+		{"/v2/items", "/v2/items"},
+	}
+
+	for _, tt := range tests {
+		if got := EndpointLabel(tt.endpoint); got != tt.want {
+			t.Errorf("EndpointLabel(%q) = %q, want %q", tt.endpoint, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package mock
+
+import (
+	"fmt"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/player"
+)
+
+// Player is an in-memory implementation of player.Reader keyed by client id
+type Player struct {
+	StatsByID map[string]player.Stats
+	Err       error
+}
+
+var _ player.Reader = (*Player)(nil)
+
+// Create a new empty mock player backend
+func NewPlayer() *Player {
+	return &Player{StatsByID: make(map[string]player.Stats)}
+}
+
+func (m *Player) Stats(clientID string) (player.Stats, error) {
+	if m.Err != nil {
+		return player.Stats{}, m.Err
+	}
+
+	stats, ok := m.StatsByID[clientID]
+	if !ok {
+		return player.Stats{}, fmt.Errorf("client %s: %w", clientID, player.ErrNoStats)
+	}
+	return stats, nil
+}
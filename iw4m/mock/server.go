@@ -0,0 +1,433 @@
+package mock
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+// Execution is a command received by the mock server
+type Execution struct {
+	ServerID string
+	Command  string
+}
+
+// Server is an in-memory implementation of server.API. Every read method
+// returns the matching field, or Err when it is set
+type Server struct {
+	Servers       []server.ServerStatus
+	ServerInfo    *server.ServerInfo
+	Map           string
+	Mode          string
+	Version       string
+	User          string
+	RuleList      []string
+	ReportList    []server.Report
+	HelpData      *server.Help
+	IDs           []server.ServerID
+	Chat          []server.Chat
+	Found         []server.FindPlayer
+	Players       []server.Players
+	StockRoleList []string
+	RoleList      []string
+	Recent        []server.RecentClient
+	Audit         []server.AuditLog
+	AdminList     []server.Admin
+	Top           []server.TopPlayer
+	// PenaltyList maps client ids to their penalties
+	PenaltyList map[string][]server.Penalty
+
+	// Levels maps client ids to their level. StockRoleList defines the
+	// assignable levels, lowest first
+	Levels map[string]string
+
+	// NextMap is loaded by RotateMap
+	NextMap string
+
+	// DefaultServerID is used by ExecuteCommand
+	DefaultServerID string
+	// Respond builds the response lines for an executed command
+	Respond func(serverID, command string) []string
+
+	Err error
+
+	mu       sync.Mutex
+	executed []Execution
+}
+
+var _ server.API = (*Server)(nil)
+
+// Create a new empty mock server
+func NewServer() *Server {
+	return &Server{
+		DefaultServerID: "0",
+		Levels:          make(map[string]string),
+	}
+}
+
+func (m *Server) Status() ([]server.ServerStatus, error) {
+	return m.Servers, m.Err
+}
+
+func (m *Server) Info() (*server.ServerInfo, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	if m.ServerInfo == nil {
+		return &server.ServerInfo{}, nil
+	}
+	return m.ServerInfo, nil
+}
+
+func (m *Server) MapName() (string, error)     { return m.Map, m.Err }
+func (m *Server) GameMode() (string, error)    { return m.Mode, m.Err }
+func (m *Server) IW4MVersion() (string, error) { return m.Version, m.Err }
+func (m *Server) LoggedInAs() (string, error)  { return m.User, m.Err }
+func (m *Server) Rules() ([]string, error)     { return m.RuleList, m.Err }
+
+func (m *Server) Reports() ([]server.Report, error) {
+	return m.ReportList, m.Err
+}
+
+func (m *Server) Help() (*server.Help, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	if m.HelpData == nil {
+		return &server.Help{Sections: map[string]server.HelpSection{}}, nil
+	}
+	return m.HelpData, nil
+}
+
+func (m *Server) ServerIDs() ([]server.ServerID, error) {
+	return m.IDs, m.Err
+}
+
+func (m *Server) ReadChat() ([]server.Chat, error) {
+	return m.Chat, m.Err
+}
+
+func (m *Server) FindPlayer(username, xuid string, count, offset, direction int) ([]server.FindPlayer, error) {
+	if username == "" && xuid == "" {
+		return nil, fmt.Errorf("username or xuid is required")
+	}
+	return m.Found, m.Err
+}
+
+func (m *Server) ListPlayers() ([]server.Players, error) {
+	return m.Players, m.Err
+}
+
+func (m *Server) StockRoles() ([]string, error) {
+	return m.StockRoleList, m.Err
+}
+
+func (m *Server) Roles() ([]string, error) {
+	return m.RoleList, m.Err
+}
+
+func (m *Server) RecentClients(offset int) ([]server.RecentClient, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	if offset >= len(m.Recent) {
+		return nil, nil
+	}
+	end := min(offset+20, len(m.Recent))
+	return m.Recent[offset:end], nil
+}
+
+func (m *Server) RecentAuditLog() (*server.AuditLog, error) {
+	if m.Err != nil || len(m.Audit) == 0 {
+		return nil, m.Err
+	}
+	return &m.Audit[0], nil
+}
+
+func (m *Server) AuditLogs(count int) ([]server.AuditLog, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	if count <= 0 {
+		count = 15
+	}
+	return m.Audit[:min(count, len(m.Audit))], nil
+}
+
+func (m *Server) Penalties(clientID string) ([]server.Penalty, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	return m.PenaltyList[clientID], nil
+}
+
+func (m *Server) Admins(role string, count int) ([]server.Admin, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	if count > 0 && count < len(m.AdminList) {
+		return m.AdminList[:count], nil
+	}
+	return m.AdminList, nil
+}
+
+func (m *Server) TopPlayers(count int) ([]server.TopPlayer, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	if count > 0 && count < len(m.Top) {
+		return m.Top[:count], nil
+	}
+	return m.Top, nil
+}
+
+func (m *Server) ExecuteCommand(command string) ([]string, error) {
+	return m.ExecuteCommandOn(m.DefaultServerID, command)
+}
+
+func (m *Server) ExecuteCommandOn(serverID, command string) ([]string, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+
+	m.mu.Lock()
+	m.executed = append(m.executed, Execution{ServerID: serverID, Command: command})
+	m.mu.Unlock()
+
+	if m.Respond != nil {
+		return m.Respond(serverID, command), nil
+	}
+	return nil, nil
+}
+
+// Return every command executed so far, in order
+func (m *Server) Executed() []Execution {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Execution(nil), m.executed...)
+}
+
+// Forget the executed commands
+func (m *Server) Reset() {
+	m.mu.Lock()
+	m.executed = nil
+	m.mu.Unlock()
+}
+
+func (m *Server) Level(clientID string) (string, error) {
+	if m.Err != nil {
+		return "", m.Err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	level, ok := m.Levels[clientID]
+	if !ok {
+		return "", fmt.Errorf("client %s: %w", clientID, server.ErrNotFound)
+	}
+	return level, nil
+}
+
+func (m *Server) SetLevel(clientID, level string) (*server.LevelChange, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, l := range m.StockRoleList {
+		if server.SameLevel(l, level) {
+			change := &server.LevelChange{
+				ClientID:  clientID,
+				Previous:  m.Levels[clientID],
+				Requested: l,
+				Current:   l,
+				Verified:  true,
+			}
+			m.Levels[clientID] = l
+			return change, nil
+		}
+	}
+	return nil, fmt.Errorf("level %q: %w", level, server.ErrNotFound)
+}
+
+func (m *Server) Promote(clientID string) (*server.LevelChange, error) {
+	return m.shiftLevel(clientID, 1)
+}
+
+func (m *Server) Demote(clientID string) (*server.LevelChange, error) {
+	return m.shiftLevel(clientID, -1)
+}
+
+func (m *Server) shiftLevel(clientID string, delta int) (*server.LevelChange, error) {
+	current, err := m.Level(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, l := range m.StockRoleList {
+		if l != current {
+			continue
+		}
+		next := i + delta
+		if next < 0 || next >= len(m.StockRoleList) {
+			return nil, fmt.Errorf("client %s is already at level %s", clientID, current)
+		}
+		return m.SetLevel(clientID, m.StockRoleList[next])
+	}
+	return nil, fmt.Errorf("current level %q of client %s is not assignable", current, clientID)
+}
+
+func (m *Server) Say(message string) error {
+	return m.SayOn(m.DefaultServerID, message)
+}
+
+func (m *Server) SayOn(serverID, message string) error {
+	return m.chat(serverID, "!say ", message)
+}
+
+func (m *Server) Tell(target, message string) error {
+	return m.TellOn(m.DefaultServerID, target, message)
+}
+
+func (m *Server) TellOn(serverID, target, message string) error {
+	return m.chat(serverID, "!privatemessage "+target+" ", message)
+}
+
+func (m *Server) Broadcast(message string) error {
+	if m.Err != nil {
+		return m.Err
+	}
+	for _, id := range m.IDs {
+		if err := m.SayOn(id.ID, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Record chat the way the real server formats it, one command per line
+func (m *Server) chat(serverID, command, message string) error {
+	for _, line := range server.SplitMessage(message, server.DefaultChatLimit) {
+		if _, err := m.ExecuteCommandOn(serverID, command+line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Server) ServerStatus(serverID string) (*server.ServerStatus, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.statusOf(serverID)
+}
+
+// ChangeMap switches the map in ServerStatus immediately
+func (m *Server) ChangeMap(serverID, name string) (*server.MapChange, error) {
+	return m.switchMap(serverID, "!map ", func(st *server.ServerStatus) (string, error) {
+		code, ok := server.ResolveMap(st.Game, name)
+		if !ok {
+			return "", fmt.Errorf("map %q for %s: %w", name, st.Game, server.ErrNotFound)
+		}
+		return code, nil
+	})
+}
+
+// RotateMap switches to the map in NextMap, or keeps the current one
+func (m *Server) RotateMap(serverID string) (*server.MapChange, error) {
+	return m.switchMap(serverID, "!maprotate", func(st *server.ServerStatus) (string, error) {
+		if m.NextMap != "" {
+			return m.NextMap, nil
+		}
+		return st.Map.Name, nil
+	})
+}
+
+func (m *Server) RestartMap(serverID string) (*server.MapChange, error) {
+	return m.switchMap(serverID, "!rcon map_restart", keepMap)
+}
+
+func (m *Server) FastRestart(serverID string) (*server.MapChange, error) {
+	return m.switchMap(serverID, "!rcon fast_restart", keepMap)
+}
+
+func keepMap(st *server.ServerStatus) (string, error) {
+	return st.Map.Name, nil
+}
+
+func (m *Server) switchMap(serverID, command string, next func(*server.ServerStatus) (string, error)) (*server.MapChange, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+
+	m.mu.Lock()
+	st, err := m.statusOf(serverID)
+	if err != nil {
+		m.mu.Unlock()
+		return nil, err
+	}
+
+	code, err := next(st)
+	if err != nil {
+		m.mu.Unlock()
+		return nil, err
+	}
+
+	change := &server.MapChange{
+		ServerID:  serverID,
+		Previous:  st.Map.Name,
+		Requested: code,
+		Current:   code,
+		GameMode:  st.GameMode,
+		Confirmed: true,
+	}
+	st.Map.Name = code
+	m.mu.Unlock()
+
+	if strings.HasSuffix(command, " ") {
+		command += code
+	}
+	m.ExecuteCommandOn(serverID, command)
+	return change, nil
+}
+
+// Must be called with m.mu held
+func (m *Server) statusOf(serverID string) (*server.ServerStatus, error) {
+	for i := range m.Servers {
+		if strconv.Itoa(m.Servers[i].ID) == serverID {
+			return &m.Servers[i], nil
+		}
+	}
+	return nil, fmt.Errorf("server %s: %w", serverID, server.ErrNotFound)
+}
+
+func (m *Server) Warn(serverID, target, reason string) error {
+	return m.punish(serverID, "!warn "+target, reason)
+}
+
+func (m *Server) Kick(serverID, target, reason string) error {
+	return m.punish(serverID, "!kick "+target, reason)
+}
+
+func (m *Server) TempBan(serverID, target string, duration time.Duration, reason string) error {
+	return m.punish(serverID, "!tempban "+target+" "+server.FormatBanDuration(duration), reason)
+}
+
+func (m *Server) Ban(serverID, target, reason string) error {
+	return m.punish(serverID, "!ban "+target, reason)
+}
+
+func (m *Server) punish(serverID, command, reason string) error {
+	_, err := m.ExecuteCommandOn(serverID, command+" "+reason)
+	return err
+}
@@ -0,0 +1,278 @@
+package moderation
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+type Message struct {
+	Time     time.Time
+	ServerID string
+	Sender   string
+	Level    string
+	Text     string
+}
+
+type Detection struct {
+	Detector string
+	Reason   string
+}
+
+// Detector inspects a chat message and reports whether it breaks a rule
+type Detector interface {
+	Name() string
+	Detect(m Message) (Detection, bool)
+}
+
+var leet = strings.NewReplacer(
+	"0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "8", "b", "9", "g",
+	"@", "a", "$", "s", "!", "i", "|", "i", "+", "t",
+)
+
+// Normalize text for word matching: lower case, leetspeak undone and
+// punctuation turned into spaces. A "!" ending a word is punctuation, not
+// an i
+func Normalize(text string) string {
+	words := strings.Fields(strings.ToLower(text))
+	for i, w := range words {
+		words[i] = leet.Replace(strings.TrimRight(w, "!"))
+	}
+	text = strings.Join(words, " ")
+
+	var b strings.Builder
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			r = ' '
+		}
+		b.WriteRune(r)
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// Collapse repeated letters, so "fuuuun" and "fun" both become "fun"
+func Squeeze(text string) string {
+	var b strings.Builder
+	var last rune
+	for _, r := range text {
+		if r == last && unicode.IsLetter(r) {
+			continue
+		}
+		b.WriteRune(r)
+		last = r
+	}
+	return b.String()
+}
+
+// Report whether text repeats a letter three or more times in a row, which
+// ordinary spelling does not
+func stretched(text string) bool {
+	var last rune
+	run := 0
+	for _, r := range text {
+		if r == last && unicode.IsLetter(r) {
+			run++
+			if run >= 3 {
+				return true
+			}
+			continue
+		}
+		last, run = r, 1
+	}
+	return false
+}
+
+// WordList detects banned words, including ones spelled in leetspeak,
+// stretched like "baaaad" or spaced out letter by letter
+type WordList struct {
+	Words []string
+}
+
+func (w *WordList) Name() string { return "profanity" }
+
+func (w *WordList) Detect(m Message) (Detection, bool) {
+	tokens := strings.Fields(Normalize(m.Text))
+	tokens = append(tokens, joinSingles(tokens)...)
+
+	for _, word := range w.Words {
+		word = Normalize(word)
+		if word == "" {
+			continue
+		}
+		if slices.ContainsFunc(tokens, func(token string) bool { return matchWord(token, word) }) {
+			return Detection{Detector: w.Name(), Reason: "Inappropriate language"}, true
+		}
+	}
+	return Detection{}, false
+}
+
+// Match a token against a banned word. Repeated letters are only ignored
+// when the token is stretched, so "as" does not match "ass" but "asssss"
+// does
+func matchWord(token, word string) bool {
+	if token == word {
+		return true
+	}
+	return stretched(token) && Squeeze(token) == Squeeze(word)
+}
+
+// Join runs of single letters, so "b a d" is checked as "bad"
+func joinSingles(tokens []string) []string {
+	var joined []string
+	run := ""
+	for _, t := range append(tokens, "") {
+		if len([]rune(t)) == 1 {
+			run += t
+			continue
+		}
+		if len(run) > 1 {
+			joined = append(joined, run)
+		}
+		run = ""
+	}
+	return joined
+}
+
+// Spam detects a sender repeating the same message
+type Spam struct {
+	// Number of identical messages that count as spam, default 3
+	Repeats int
+	// Window the repeats must fall into, default 30s
+	Window time.Duration
+
+	mu   sync.Mutex
+	sent map[string][]time.Time
+}
+
+func (s *Spam) Name() string { return "spam" }
+
+func (s *Spam) Detect(m Message) (Detection, bool) {
+	repeats, window := s.Repeats, s.Window
+	if repeats <= 1 {
+		repeats = 3
+	}
+	if window <= 0 {
+		window = 30 * time.Second
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sent == nil {
+		s.sent = make(map[string][]time.Time)
+	}
+
+	key := m.Sender + "\x00" + Normalize(m.Text)
+	times := slices.DeleteFunc(s.sent[key], func(t time.Time) bool {
+		return m.Time.Sub(t) > window
+	})
+	times = append(times, m.Time)
+	s.sent[key] = times
+
+	// Drop expired senders so the map does not grow forever
+	for k, ts := range s.sent {
+		if len(ts) > 0 && m.Time.Sub(ts[len(ts)-1]) > window {
+			delete(s.sent, k)
+		}
+	}
+
+	if len(times) >= repeats {
+		return Detection{Detector: s.Name(), Reason: "Spamming"}, true
+	}
+	return Detection{}, false
+}
+
+// Caps detects messages written mostly in capital letters
+type Caps struct {
+	// Messages with fewer letters are ignored, default 10
+	MinLetters int
+	// Share of capital letters that counts as shouting, default 0.7
+	Ratio float64
+}
+
+func (c *Caps) Name() string { return "caps" }
+
+func (c *Caps) Detect(m Message) (Detection, bool) {
+	minLetters, ratio := c.MinLetters, c.Ratio
+	if minLetters <= 0 {
+		minLetters = 10
+	}
+	if ratio <= 0 {
+		ratio = 0.7
+	}
+
+	letters, upper := 0, 0
+	for _, r := range server.StripColors(m.Text) {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+
+	if letters >= minLetters && float64(upper)/float64(letters) >= ratio {
+		return Detection{Detector: c.Name(), Reason: "Excessive caps"}, true
+	}
+	return Detection{}, false
+}
+
+var (
+	ipPattern = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}(?::\d{2,5})?\b`)
+	// Bare domains need the dot written without spaces, as in "site.com",
+	// or spelled out as in "site (dot) com". The top level domain must not
+	// run on into a longer word such as "co-op"
+	urlPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+|\b[a-z0-9-]+(?:\.|\s?(?:\(dot\)|\[dot\])\s?)(?:com|net|org|gg|io|xyz|me|tv|de|uk|ru|co)(?:$|[^a-z0-9-])`)
+	dotPattern = regexp.MustCompile(`\s?(?:\(dot\)|\[dot\])\s?`)
+)
+
+// Advertising detects IP addresses and links to other sites
+type Advertising struct {
+	// Domains that may be posted along with their subdomains, e.g. the
+	// community's own site
+	Allow []string
+}
+
+func (a *Advertising) Name() string { return "advertising" }
+
+func (a *Advertising) Detect(m Message) (Detection, bool) {
+	text := server.StripColors(m.Text)
+
+	if ipPattern.MatchString(text) {
+		return Detection{Detector: a.Name(), Reason: "Advertising"}, true
+	}
+
+	for _, match := range urlPattern.FindAllString(text, -1) {
+		host := linkHost(match)
+		allowed := slices.ContainsFunc(a.Allow, func(domain string) bool {
+			domain = strings.Trim(strings.ToLower(domain), ".")
+			return host == domain || strings.HasSuffix(host, "."+domain)
+		})
+		if !allowed {
+			return Detection{Detector: a.Name(), Reason: "Advertising"}, true
+		}
+	}
+	return Detection{}, false
+}
+
+// Return the lowercase host name of a link found by urlPattern
+func linkHost(link string) string {
+	host := dotPattern.ReplaceAllString(strings.ToLower(link), ".")
+	if _, rest, ok := strings.Cut(host, "://"); ok {
+		host = rest
+	}
+	if i := strings.IndexAny(host, "/?#"); i >= 0 {
+		host = host[:i]
+	}
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		host = host[i+1:]
+	}
+	host, _, _ = strings.Cut(host, ":")
+	return strings.TrimRightFunc(host, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
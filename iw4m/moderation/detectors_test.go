@@ -0,0 +1,162 @@
+package moderation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Hello, World!", "hello world"},
+		{"b4d w0rd", "bad word"},
+		{"  spaced   out  ", "spaced out"},
+		{"too good", "too good"},
+		{"f.u.n", "f u n"},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.text); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSqueeze(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"fuuuun", "fun"},
+		{"ass", "as"},
+		{"book keeper", "bok keper"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Squeeze(tt.text); got != tt.want {
+			t.Errorf("Squeeze(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestWordList(t *testing.T) {
+	w := &WordList{Words: []string{"ass", "butt", "noob"}}
+
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"as if", false},
+		{"but why", false},
+		{"nice shot", false},
+		{"passing through", false},
+		{"you ass", true},
+		{"ASS", true},
+		{"@$$", true},
+		{"asssss", true},
+		{"buuuutt", true},
+		{"n00b", true},
+		{"n o o b", true},
+		{"n.o.o.b", true},
+		{"nooooob", true},
+		{"nob", false},
+	}
+
+	for _, tt := range tests {
+		_, got := w.Detect(Message{Text: tt.text})
+		if got != tt.want {
+			t.Errorf("WordList.Detect(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestAdvertising(t *testing.T) {
+	a := &Advertising{Allow: []string{"ourclan.com"}}
+
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"nice shot. me next", false},
+		{"gg. co-op later", false},
+		{"gg.co-op later", false},
+		{"score 1,000,000,000", false},
+		{"version 1.2.3", false},
+		{"join 192.168.1.10:28960", true},
+		{"join 192.168.1.10", true},
+		{"play at bestserver.com", true},
+		{"play at bestserver.com/join now", true},
+		{"bestserver (dot) net", true},
+		{"bestserver[dot]gg", true},
+		{"www.example.org", true},
+		{"https://example.xyz/invite", true},
+		{"visit ourclan.com", false},
+		{"visit OurClan.com.", false},
+		{"forums.ourclan.com", false},
+		{"https://forums.ourclan.com/join?id=1", false},
+		{"ourclan (dot) com", false},
+		{"evilourclan.com", true},
+		{"https://evilourclan.com", true},
+		{"ourclan.com.evil.ru", true},
+		{"www.ourclan.com.evil.ru", true},
+		{"https://ourclan.com@evil.ru/join", true},
+		{"https://ourclan.com.evil.ru:28960", true},
+		{"^1play ^2at ^3evil.gg", true},
+	}
+
+	for _, tt := range tests {
+		_, got := a.Detect(Message{Text: tt.text})
+		if got != tt.want {
+			t.Errorf("Advertising.Detect(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCaps(t *testing.T) {
+	c := &Caps{}
+
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"STOP CAMPING RIGHT NOW", true},
+		{"GG", false},
+		{"Nice shot everyone", false},
+		{"^1WHY ^2IS THIS SO LOUD", true},
+	}
+
+	for _, tt := range tests {
+		_, got := c.Detect(Message{Text: tt.text})
+		if got != tt.want {
+			t.Errorf("Caps.Detect(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSpam(t *testing.T) {
+	s := &Spam{Repeats: 3, Window: 10 * time.Second}
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		offset time.Duration
+		sender string
+		text   string
+		want   bool
+	}{
+		{0, "a", "buy now", false},
+		{time.Second, "b", "buy now", false},
+		{2 * time.Second, "a", "BUY NOW!", false},
+		{3 * time.Second, "a", "buy   now", true},
+		{30 * time.Second, "a", "buy now", false},
+	}
+
+	for _, tt := range tests {
+		_, got := s.Detect(Message{Time: start.Add(tt.offset), Sender: tt.sender, Text: tt.text})
+		if got != tt.want {
+			t.Errorf("Spam.Detect(%s, %q at +%s) = %v, want %v", tt.sender, tt.text, tt.offset, got, tt.want)
+		}
+	}
+}
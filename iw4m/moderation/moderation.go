@@ -0,0 +1,169 @@
+package moderation
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/events"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+// Backend is the part of the server API moderation needs
+type Backend interface {
+	Status() ([]server.ServerStatus, error)
+	TellOn(serverID, target, message string) error
+	Warn(serverID, target, reason string) error
+	Kick(serverID, target, reason string) error
+	TempBan(serverID, target string, duration time.Duration, reason string) error
+}
+
+type Action string
+
+const (
+	Warn    Action = "warn"
+	Kick    Action = "kick"
+	TempBan Action = "tempban"
+	// Notify only tells online admins, the sender is not punished
+	Notify Action = "notify"
+)
+
+// Filter pairs a detector with the action taken when it fires
+type Filter struct {
+	Detector Detector
+	Action   Action
+	// Length of the ban for TempBan, default 1h
+	Duration time.Duration
+	// Also tell online admins about the detection
+	NotifyAdmins bool
+}
+
+type Config struct {
+	// Filters are checked in order and the first match is acted on
+	Filters []Filter
+	// Levels that are never filtered, default Trusted and above
+	Exempt []string
+	// Lowest level told about detections, default Moderator
+	AdminLevel string
+	// Called for every detection, nil ignores them
+	OnDetect func(Message, Detection, Action)
+	// Called when acting on a detection fails, nil ignores errors
+	OnError func(error)
+}
+
+// Pipeline runs new chat messages through the filters
+type Pipeline struct {
+	backend Backend
+	cfg     Config
+}
+
+// Create a new moderation pipeline
+func New(backend Backend, cfg Config) *Pipeline {
+	if cfg.Exempt == nil {
+		cfg.Exempt = slices.Clone(server.StockLevels[server.LevelRank("Trusted"):])
+	}
+	if cfg.AdminLevel == "" {
+		cfg.AdminLevel = "Moderator"
+	}
+	for i := range cfg.Filters {
+		if cfg.Filters[i].Duration <= 0 {
+			cfg.Filters[i].Duration = time.Hour
+		}
+	}
+
+	return &Pipeline{backend: backend, cfg: cfg}
+}
+
+// Handle chat events from an events.Watcher
+func (p *Pipeline) Handle(ev events.Event) {
+	if ev.Type != events.Chat {
+		return
+	}
+
+	if ev.ServerID == "" || ev.Player == nil {
+		return // the sender already left or the line came from the console
+	}
+
+	m := Message{
+		Time:     ev.Time,
+		ServerID: ev.ServerID,
+		Sender:   ev.Sender,
+		Level:    ev.Player.Level,
+		Text:     ev.Message,
+	}
+
+	filter, detection, ok := p.Check(m)
+	if !ok {
+		return
+	}
+
+	if err := p.act(m, ev.Player.ClientNumber, filter, detection); err != nil {
+		p.fail(err)
+	}
+	if p.cfg.OnDetect != nil {
+		p.cfg.OnDetect(m, detection, filter.Action)
+	}
+}
+
+// Run a message through the filters and return the first that fires.
+// Messages from exempt levels never match
+func (p *Pipeline) Check(m Message) (Filter, Detection, bool) {
+	if slices.ContainsFunc(p.cfg.Exempt, func(l string) bool { return server.SameLevel(l, m.Level) }) {
+		return Filter{}, Detection{}, false
+	}
+
+	for _, f := range p.cfg.Filters {
+		if d, ok := f.Detector.Detect(m); ok {
+			return f, d, true
+		}
+	}
+	return Filter{}, Detection{}, false
+}
+
+func (p *Pipeline) act(m Message, slot int, f Filter, d Detection) error {
+	target := server.BySlot(slot)
+
+	var errs []error
+	switch f.Action {
+	case Warn:
+		errs = append(errs, p.backend.Warn(m.ServerID, target, d.Reason))
+	case Kick:
+		errs = append(errs, p.backend.Kick(m.ServerID, target, d.Reason))
+	case TempBan:
+		errs = append(errs, p.backend.TempBan(m.ServerID, target, f.Duration, d.Reason))
+	}
+
+	if f.Action == Notify || f.NotifyAdmins {
+		errs = append(errs, p.notify(m, d))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Tell every online admin about a detection
+func (p *Pipeline) notify(m Message, d Detection) error {
+	status, err := p.backend.Status()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	notice := "^1[" + d.Detector + "] ^7" + server.StripColors(m.Sender) + ": " + server.StripColors(m.Text)
+	for _, s := range status {
+		id := strconv.Itoa(s.ID)
+		for _, admin := range s.Players {
+			if server.LevelRank(admin.Level) >= server.LevelRank(p.cfg.AdminLevel) {
+				errs = append(errs, p.backend.TellOn(id, server.BySlot(admin.ClientNumber), notice))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (p *Pipeline) fail(err error) {
+	if p.cfg.OnError != nil {
+		p.cfg.OnError(err)
+	}
+}
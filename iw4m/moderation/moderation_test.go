@@ -0,0 +1,71 @@
+package moderation
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/events"
+	"github.com/Yallamaztar/iw4m-go/iw4m/mock"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+func TestHandle(t *testing.T) {
+	admins := server.ServerStatus{ID: 2, Players: []server.PlayerStatus{
+		{Name: "mod", ClientNumber: 4, Level: "Moderator"},
+		{Name: "user", ClientNumber: 5, Level: "User"},
+	}}
+
+	tests := []struct {
+		name   string
+		action Action
+		ev     events.Event
+		want   []mock.Execution
+	}{
+		{
+			name:   "warn on the sender's server",
+			action: Warn,
+			ev: events.Event{ServerID: "1", Sender: "^1bob", Message: "join evil.gg",
+				Player: &events.Player{Name: "^1bob", ClientNumber: 3, Level: "User"}},
+			want: []mock.Execution{{ServerID: "1", Command: "!warn 3 Advertising"}},
+		},
+		{
+			name:   "notify admins on every server",
+			action: Notify,
+			ev: events.Event{ServerID: "1", Sender: "^1bob", Message: "join evil.gg",
+				Player: &events.Player{Name: "^1bob", ClientNumber: 3, Level: "User"}},
+			want: []mock.Execution{{ServerID: "2", Command: "!privatemessage 4 ^1[advertising] ^7bob: join evil.gg"}},
+		},
+		{
+			name:   "exempt level",
+			action: Kick,
+			ev: events.Event{ServerID: "1", Sender: "vip", Message: "join evil.gg",
+				Player: &events.Player{Name: "vip", ClientNumber: 3, Level: "Trusted"}},
+		},
+		{
+			name:   "sender not found",
+			action: Kick,
+			ev:     events.Event{Sender: "Console", Message: "join evil.gg"},
+		},
+		{
+			name:   "clean message",
+			action: Kick,
+			ev: events.Event{ServerID: "1", Sender: "bob", Message: "gg",
+				Player: &events.Player{Name: "bob", ClientNumber: 3, Level: "User"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := mock.NewServer()
+			backend.Servers = []server.ServerStatus{admins}
+			p := New(backend, Config{Filters: []Filter{{Detector: &Advertising{}, Action: tt.action}}})
+
+			tt.ev.Type = events.Chat
+			p.Handle(tt.ev)
+
+			if got := backend.Executed(); !slices.Equal(got, tt.want) {
+				t.Errorf("executed %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package namepolicy
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/events"
+	"github.com/Yallamaztar/iw4m-go/iw4m/moderation"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+// Backend is the part of the server API the name policy needs
+type Backend interface {
+	Admins(role string, count int) ([]server.Admin, error)
+	TellOn(serverID, target, message string) error
+	Kick(serverID, target, reason string) error
+	TempBan(serverID, target string, duration time.Duration, reason string) error
+	Ban(serverID, target, reason string) error
+}
+
+type Rule string
+
+const (
+	BannedWord    Rule = "banned_word"
+	Impersonation Rule = "impersonation"
+	ClanTag       Rule = "clan_tag"
+	TooShort      Rule = "too_short"
+	Unicode       Rule = "unicode"
+)
+
+type Action string
+
+const (
+	Tell    Action = "tell"
+	Kick    Action = "kick"
+	TempBan Action = "tempban"
+	Ban     Action = "ban"
+)
+
+type Response struct {
+	Action Action
+	// Reason shown to the player
+	Message string
+	// Length of the ban for TempBan, default 1d
+	Duration time.Duration
+}
+
+type Config struct {
+	BannedWords []string
+	// Minimum length of the name without color codes and spaces
+	MinLength int
+	// Tags such as "[ABC]" that only the given levels may wear
+	ProtectedTags map[string][]string
+	// A tag every player must wear, empty disables the requirement
+	RequiredTag string
+	// Combining marks allowed in a name before it counts as abuse, default 2
+	MaxCombining int
+	// Levels that are never checked, default Moderator and above
+	Exempt []string
+	// How long the staff list from Admins() is cached, default 10m
+	StaffRefresh time.Duration
+	// Response per rule, rules without one are kicked
+	Responses map[Rule]Response
+	// Called for every violation, nil ignores them
+	OnViolation func(serverID string, player events.Player, v Violation)
+	// Called when a check or action fails, nil ignores errors
+	OnError func(error)
+}
+
+type Violation struct {
+	Rule   Rule
+	Detail string
+}
+
+// Policy checks the names of joining players
+type Policy struct {
+	backend Backend
+	cfg     Config
+	words   *moderation.WordList
+	// ProtectedTags keys in sorted order, so the reported tag does not
+	// depend on map iteration
+	tags []string
+
+	mu      sync.Mutex
+	staff   []server.Admin
+	fetched time.Time
+}
+
+// Create a new name policy
+func New(backend Backend, cfg Config) *Policy {
+	if cfg.MaxCombining <= 0 {
+		cfg.MaxCombining = 2
+	}
+	if cfg.Exempt == nil {
+		cfg.Exempt = slices.Clone(server.StockLevels[server.LevelRank("Moderator"):])
+	}
+	if cfg.StaffRefresh <= 0 {
+		cfg.StaffRefresh = 10 * time.Minute
+	}
+
+	return &Policy{
+		backend: backend,
+		cfg:     cfg,
+		words:   &moderation.WordList{Words: cfg.BannedWords},
+		tags:    slices.Sorted(maps.Keys(cfg.ProtectedTags)),
+	}
+}
+
+// Handle join events from an events.Watcher
+func (p *Policy) Handle(ev events.Event) {
+	if ev.Type != events.PlayerJoin || ev.Player == nil {
+		return
+	}
+
+	v, ok := p.Check(ev.Player.Name, ev.Player.Level)
+	if !ok {
+		return
+	}
+
+	if err := p.respond(ev.ServerID, *ev.Player, v); err != nil {
+		p.fail(err)
+	}
+	if p.cfg.OnViolation != nil {
+		p.cfg.OnViolation(ev.ServerID, *ev.Player, v)
+	}
+}
+
+// Check a name against the rules and return the first violation
+func (p *Policy) Check(name, level string) (Violation, bool) {
+	if slices.ContainsFunc(p.cfg.Exempt, func(l string) bool { return server.SameLevel(l, level) }) {
+		return Violation{}, false
+	}
+
+	stripped := strings.TrimSpace(server.StripColors(name))
+
+	if detail, bad := unicodeAbuse(stripped, p.cfg.MaxCombining); bad {
+		return Violation{Rule: Unicode, Detail: detail}, true
+	}
+
+	if p.cfg.MinLength > 0 {
+		length := utf8.RuneCountInString(strings.ReplaceAll(stripped, " ", ""))
+		if length < p.cfg.MinLength {
+			return Violation{Rule: TooShort, Detail: fmt.Sprintf("%d characters", length)}, true
+		}
+	}
+
+	if _, bad := p.words.Detect(moderation.Message{Text: stripped}); bad {
+		return Violation{Rule: BannedWord}, true
+	}
+
+	lower := strings.ToLower(stripped)
+	for _, tag := range p.tags {
+		if strings.Contains(lower, strings.ToLower(tag)) &&
+			!slices.ContainsFunc(p.cfg.ProtectedTags[tag], func(l string) bool { return server.SameLevel(l, level) }) {
+			return Violation{Rule: ClanTag, Detail: "wears protected tag " + tag}, true
+		}
+	}
+	if p.cfg.RequiredTag != "" && !strings.Contains(lower, strings.ToLower(p.cfg.RequiredTag)) {
+		return Violation{Rule: ClanTag, Detail: "missing tag " + p.cfg.RequiredTag}, true
+	}
+
+	if staff, ok := p.impersonates(stripped, level); ok {
+		return Violation{Rule: Impersonation, Detail: "resembles " + staff}, true
+	}
+
+	return Violation{}, false
+}
+
+// Report the staff member a name resembles, unless the player holds that
+// staff member's level and the exact same name
+func (p *Policy) impersonates(name, level string) (string, bool) {
+	// A failed refresh still returns the list fetched before
+	staff, err := p.staffList()
+	if err != nil {
+		p.fail(err)
+	}
+
+	key := skeleton(name)
+	if key == "" {
+		return "", false
+	}
+
+	for _, admin := range staff {
+		adminName := server.StripColors(admin.Name)
+		if strings.EqualFold(adminName, name) && server.SameLevel(admin.Role, level) {
+			continue
+		}
+
+		other := skeleton(adminName)
+		if other == "" {
+			continue
+		}
+		if key == other || len(other) >= 5 && distance(key, other) <= 1 {
+			return adminName, true
+		}
+	}
+	return "", false
+}
+
+func (p *Policy) staffList() ([]server.Admin, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if time.Since(p.fetched) < p.cfg.StaffRefresh {
+		return p.staff, nil
+	}
+
+	staff, err := p.backend.Admins("all", 0)
+	if err != nil {
+		return p.staff, err
+	}
+	p.staff, p.fetched = staff, time.Now()
+	return staff, nil
+}
+
+func (p *Policy) respond(serverID string, player events.Player, v Violation) error {
+	r, ok := p.cfg.Responses[v.Rule]
+	if !ok {
+		r = Response{Action: Kick}
+	}
+	if r.Message == "" {
+		r.Message = "Your name is not allowed here, please change it"
+	}
+
+	target := server.BySlot(player.ClientNumber)
+	switch r.Action {
+	case Tell:
+		return p.backend.TellOn(serverID, target, r.Message)
+	case TempBan:
+		if r.Duration <= 0 {
+			r.Duration = 24 * time.Hour
+		}
+		return p.backend.TempBan(serverID, target, r.Duration, r.Message)
+	case Ban:
+		return p.backend.Ban(serverID, target, r.Message)
+	}
+	return p.backend.Kick(serverID, target, r.Message)
+}
+
+func (p *Policy) fail(err error) {
+	if p.cfg.OnError != nil {
+		p.cfg.OnError(err)
+	}
+}
+
+// Report names that hide behind invisible or direction changing characters,
+// stack combining marks or contain nothing visible at all
+func unicodeAbuse(name string, maxCombining int) (string, bool) {
+	if !utf8.ValidString(name) {
+		return "invalid encoding", true
+	}
+
+	visible, combining := 0, 0
+	for _, r := range name {
+		switch {
+		case unicode.Is(unicode.Cf, r) || unicode.IsControl(r):
+			return fmt.Sprintf("invisible character %U", r), true
+		case unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r):
+			combining++
+		case unicode.IsGraphic(r) && !unicode.IsSpace(r):
+			visible++
+		}
+	}
+
+	if combining > maxCombining {
+		return fmt.Sprintf("%d combining marks", combining), true
+	}
+	if visible == 0 {
+		return "no visible characters", true
+	}
+	return "", false
+}
+
+// Reduce a name to a form where look-alikes collide: no clan tags or
+// punctuation, leetspeak undone and common homoglyphs folded
+func skeleton(name string) string {
+	name = clanTags.ReplaceAllString(name, "")
+	name = homoglyphs.Replace(strings.ToLower(name))
+	name = strings.ReplaceAll(moderation.Normalize(name), " ", "")
+	// Fold look-alike letter pairs before squeezing, which would turn
+	// "vv" into "v"
+	name = strings.NewReplacer("rn", "m", "vv", "w").Replace(name)
+	return moderation.Squeeze(name)
+}
+
+var clanTags = regexp.MustCompile(`\[[^\]]*\]|\([^)]*\)|\{[^}]*\}`)
+
+var homoglyphs = strings.NewReplacer(
+	"а", "a", "е", "e", "о", "o", "р", "p", "с", "c", "у", "y", "х", "x",
+	"і", "i", "ј", "j", "ԁ", "d", "ѕ", "s", "ι", "i", "ο", "o", "ı", "i",
+	"l", "i",
+)
+
+// Levenshtein distance between two strings
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
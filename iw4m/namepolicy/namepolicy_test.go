@@ -0,0 +1,242 @@
+package namepolicy
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/events"
+	"github.com/Yallamaztar/iw4m-go/iw4m/mock"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+// A mock server that counts staff list requests
+type countingBackend struct {
+	*mock.Server
+	calls int
+}
+
+func (c *countingBackend) Admins(role string, count int) ([]server.Admin, error) {
+	c.calls++
+	return c.Server.Admins(role, count)
+}
+
+func newBackend(admins ...server.Admin) *countingBackend {
+	backend := mock.NewServer()
+	backend.AdminList = admins
+	return &countingBackend{Server: backend}
+}
+
+func TestSkeleton(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Admin", "admin"},
+		{"[ABC] Admin", "admin"},
+		{"Mod (xyz)", "mod"},
+		{"{T}Ghost", "ghost"},
+		{"AAdmin!", "admin"},
+		{"Adm1n", "admin"},
+		{"a d m i n", "admin"},
+		{"rnike", "mike"},
+		{"vvolf", "woif"},
+		{"wolf", "woif"},
+		{"[only tag]", ""},
+	}
+
+	for _, tt := range tests {
+		if got := skeleton(tt.in); got != tt.want {
+			t.Errorf("skeleton(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestHomoglyphs(t *testing.T) {
+	tests := []struct {
+		spoof, name string
+	}{
+		{"аdmin", "admin"},   // Cyrillic a
+		{"ѕnіреr", "sniper"}, // Cyrillic s, i, p and e
+		{"Gοd", "God"},       // Greek omicron
+		{"Pıxel", "Pixel"},   // dotless i
+		{"Lucky", "Iucky"},   // l and I
+	}
+
+	for _, tt := range tests {
+		if a, b := skeleton(tt.spoof), skeleton(tt.name); a != b {
+			t.Errorf("skeleton(%q) = %q, skeleton(%q) = %q, want them equal", tt.spoof, a, tt.name, b)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"admin", "admin", 0},
+		{"admin", "admn", 1},
+		{"admin", "admins", 1},
+		{"admin", "odmin", 1},
+		{"kitten", "sitting", 3},
+		{"ädmin", "admin", 1},
+	}
+
+	for _, tt := range tests {
+		if got := distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("distance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestUnicodeAbuse(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"Player", false},
+		{"Plàyer", false},
+		{"Zalgó̂", false},
+		{"Zalgó̂̃", true},
+		{"Right‮Left", true},
+		{"Zero​Width", true},
+		{"Tab\tName", true},
+		{"́̂", true},
+		{"　", true},
+		{"\xff\xfe", true},
+	}
+
+	for _, tt := range tests {
+		if _, got := unicodeAbuse(tt.name, 2); got != tt.want {
+			t.Errorf("unicodeAbuse(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	cfg := Config{
+		BannedWords: []string{"cheater"},
+		MinLength:   3,
+		ProtectedTags: map[string][]string{
+			"[B]":  {"Trusted"},
+			"[A]":  {"Trusted"},
+			"[VIP": {"Trusted"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		cfg    Config
+		player string
+		level  string
+		want   Violation
+	}{
+		{"clean", cfg, "Player", "User", Violation{}},
+		{"exempt", cfg, "[A] x", "Moderator", Violation{}},
+		{"unicode", cfg, "Bad‮Name", "User", Violation{Rule: Unicode, Detail: "invisible character U+202E"}},
+		{"too short", cfg, "^1a b", "User", Violation{Rule: TooShort, Detail: "2 characters"}},
+		{"banned word", cfg, "Ch3ater", "User", Violation{Rule: BannedWord}},
+		{"protected tag", cfg, "[a] Player", "User", Violation{Rule: ClanTag, Detail: "wears protected tag [A]"}},
+		{"first protected tag in order", cfg, "[B][A][VIP] Player", "User", Violation{Rule: ClanTag, Detail: "wears protected tag [A]"}},
+		{"protected tag allowed for level", cfg, "[A] Player", "Trusted", Violation{}},
+		{"required tag", Config{RequiredTag: "[CLAN]"}, "Player", "User", Violation{Rule: ClanTag, Detail: "missing tag [CLAN]"}},
+		{"required tag worn", Config{RequiredTag: "[CLAN]"}, "^1[clan] Player", "User", Violation{}},
+		{"impersonation", cfg, "[X] Adm1n", "User", Violation{Rule: Impersonation, Detail: "resembles Admin"}},
+		{"near impersonation", cfg, "Sniperr", "User", Violation{Rule: Impersonation, Detail: "resembles Sniper"}},
+		{"short names must match exactly", cfg, "Gods", "User", Violation{}},
+		{"the staff member", cfg, "^2Sniper", "Administrator", Violation{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := newBackend(
+				server.Admin{Name: "^1Admin", Role: "Owner"},
+				server.Admin{Name: "Sniper", Role: "Administrator"},
+				server.Admin{Name: "God", Role: "SeniorAdmin"},
+			)
+			p := New(backend, tt.cfg)
+
+			// Run several times as map order used to change the result
+			for range 10 {
+				got, _ := p.Check(tt.player, tt.level)
+				if got != tt.want {
+					t.Fatalf("Check(%q) = %+v, want %+v", tt.player, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestStaffCache(t *testing.T) {
+	backend := newBackend(server.Admin{Name: "Admin", Role: "Owner"})
+	p := New(backend, Config{StaffRefresh: time.Hour})
+
+	for range 3 {
+		if _, ok := p.Check("Adm1n", "User"); !ok {
+			t.Fatal("impersonation not detected")
+		}
+	}
+	if backend.calls != 1 {
+		t.Errorf("staff list fetched %d times, want 1", backend.calls)
+	}
+
+	// A failed refresh keeps using the staff list fetched before
+	var errs []error
+	p.cfg.OnError = func(err error) { errs = append(errs, err) }
+	p.fetched = time.Now().Add(-2 * time.Hour)
+	backend.Err = errors.New("offline")
+
+	if _, ok := p.Check("Adm1n", "User"); !ok {
+		t.Error("impersonation not detected with the cached staff list")
+	}
+	if backend.calls != 2 || len(errs) != 1 {
+		t.Errorf("%d fetches and %d errors, want 2 and 1", backend.calls, len(errs))
+	}
+}
+
+func TestRespond(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses map[Rule]Response
+		want      string
+	}{
+		{"default kick", nil, "!kick 7 Your name is not allowed here, please change it"},
+		{"tell", map[Rule]Response{TooShort: {Action: Tell, Message: "Longer please"}}, "!privatemessage 7 Longer please"},
+		{"tempban default length", map[Rule]Response{TooShort: {Action: TempBan, Message: "Bye"}}, "!tempban 7 1d Bye"},
+		{"tempban", map[Rule]Response{TooShort: {Action: TempBan, Message: "Bye", Duration: 2 * time.Hour}}, "!tempban 7 2h Bye"},
+		{"ban", map[Rule]Response{TooShort: {Action: Ban, Message: "Bye"}}, "!ban 7 Bye"},
+		{"response for another rule", map[Rule]Response{BannedWord: {Action: Ban}}, "!kick 7 Your name is not allowed here, please change it"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := newBackend()
+			var violations []Violation
+			p := New(backend, Config{
+				MinLength: 3,
+				Responses: tt.responses,
+				OnViolation: func(serverID string, player events.Player, v Violation) {
+					violations = append(violations, v)
+				},
+			})
+
+			p.Handle(events.Event{
+				Type:     events.PlayerJoin,
+				ServerID: "2",
+				Player:   &events.Player{Name: "ab", ClientNumber: 7, Level: "User"},
+			})
+
+			want := []mock.Execution{{ServerID: "2", Command: tt.want}}
+			if got := backend.Executed(); !slices.Equal(got, want) {
+				t.Errorf("executed %q, want %q", got, want)
+			}
+			if len(violations) != 1 || violations[0].Rule != TooShort {
+				t.Errorf("reported %+v, want one too short violation", violations)
+			}
+		})
+	}
+}
@@ -0,0 +1,6 @@
+package player
+
+import "errors"
+
+// ErrNoStats is returned when the webfront has no stats for a client
+var ErrNoStats = errors.New("no stats found")
@@ -0,0 +1,8 @@
+package player
+
+// Reader is the read-only surface of a player lookup backend
+type Reader interface {
+	Stats(clientID string) (Stats, error)
+}
+
+var _ Reader = (*Player)(nil)
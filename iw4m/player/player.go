@@ -32,5 +32,9 @@ func (p *Player) Stats(clientID string) (Stats, error) {
 		return Stats{}, err
 	}
 
+	if len(statsSlice) == 0 {
+		return Stats{}, fmt.Errorf("client %s: %w", clientID, ErrNoStats)
+	}
+
 	return statsSlice[0], nil
 }
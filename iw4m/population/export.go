@@ -0,0 +1,156 @@
+package population
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Write the heatmaps of reports as CSV, one row per server, weekday and
+// hour with samples
+func WriteCSV(w io.Writer, reports []Report) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"server_id", "server", "weekday", "hour", "average", "peak", "samples"}); err != nil {
+		return err
+	}
+
+	for _, r := range reports {
+		for d, hours := range r.Heatmap {
+			for h, cell := range hours {
+				if cell.Samples == 0 {
+					continue
+				}
+				err := cw.Write([]string{
+					r.ServerID,
+					r.Name,
+					Weekdays[d].String(),
+					strconv.Itoa(h),
+					strconv.FormatFloat(cell.Average, 'f', 2, 64),
+					strconv.Itoa(cell.Peak),
+					strconv.Itoa(cell.Samples),
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// Write the weekly trends of reports as CSV, one row per server and week
+func WriteTrendCSV(w io.Writer, reports []Report) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"server_id", "server", "week", "average", "peak", "samples", "change"}); err != nil {
+		return err
+	}
+
+	for _, r := range reports {
+		for _, wk := range r.Weeks {
+			change := ""
+			if wk.Change != nil {
+				change = strconv.FormatFloat(*wk.Change, 'f', 4, 64)
+			}
+			err := cw.Write([]string{
+				r.ServerID,
+				r.Name,
+				wk.Start.Format("2006-01-02"),
+				strconv.FormatFloat(wk.Average, 'f', 2, 64),
+				strconv.Itoa(wk.Peak),
+				strconv.Itoa(wk.Samples),
+				change,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func WriteJSON(w io.Writer, reports []Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(reports)
+}
+
+// Size of a heatmap cell and the margins for labels, in pixels
+const (
+	cellSize   = 28
+	leftMargin = 48
+	topMargin  = 44
+)
+
+// Write a stand-alone SVG heatmap of a report's average population. Cells
+// are shaded relative to the busiest hour and cells without samples are
+// left grey
+func WriteSVG(w io.Writer, r Report) error {
+	busiest := 0.0
+	for _, hours := range r.Heatmap {
+		for _, cell := range hours {
+			busiest = max(busiest, cell.Average)
+		}
+	}
+
+	width := leftMargin + 24*cellSize + 10
+	height := topMargin + 7*cellSize + 10
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="11">`+"\n", width, height)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", width, height)
+
+	title := r.Name
+	if title == "" {
+		title = "Server " + r.ServerID
+	}
+	fmt.Fprintf(&b, `<text x="%d" y="18" font-size="14" font-weight="bold">%s</text>`+"\n", leftMargin, html.EscapeString(title))
+
+	for h := range 24 {
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle">%02d</text>`+"\n",
+			leftMargin+h*cellSize+cellSize/2, topMargin-6, h)
+	}
+
+	for d, hours := range r.Heatmap {
+		y := topMargin + d*cellSize
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`+"\n",
+			leftMargin-6, y+cellSize/2+4, Weekdays[d].String()[:3])
+
+		for h, cell := range hours {
+			x := leftMargin + h*cellSize
+			fill := "#eeeeee"
+			if cell.Samples > 0 {
+				fill = shade(cell.Average, busiest)
+			}
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s" stroke="#ffffff"><title>%s %02d:00 avg %.1f peak %d (%d samples)</title></rect>`+"\n",
+				x, y, cellSize, cellSize, fill, Weekdays[d], h, cell.Average, cell.Peak, cell.Samples)
+		}
+	}
+
+	b.WriteString("</svg>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Blend from a pale to a deep blue by value relative to busiest
+func shade(value, busiest float64) string {
+	f := 0.0
+	if busiest > 0 {
+		f = value / busiest
+	}
+	from := [3]float64{0xe8, 0xf1, 0xfa}
+	to := [3]float64{0x08, 0x30, 0x6b}
+
+	var c [3]int
+	for i := range c {
+		c[i] = int(from[i] + (to[i]-from[i])*f + 0.5)
+	}
+	return fmt.Sprintf("#%02x%02x%02x", c[0], c[1], c[2])
+}
@@ -0,0 +1,104 @@
+package population
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func exportReport() Report {
+	change := -0.25
+	r := Report{
+		ServerID: "1",
+		Name:     `Clan "A", <Best>`,
+		Weeks: []Week{
+			{Start: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), Average: 4, Peak: 8, Samples: 10},
+			{Start: time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), Average: 3, Peak: 5, Samples: 12, Change: &change},
+		},
+	}
+	r.Heatmap[0][9] = Cell{Average: 1.5, Peak: 3, Samples: 2}
+	r.Heatmap[6][23] = Cell{Average: 6, Peak: 6, Samples: 1}
+	return r
+}
+
+func TestWriteCSV(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteCSV(&b, []Report{exportReport()}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `server_id,server,weekday,hour,average,peak,samples
+1,"Clan ""A"", <Best>",Monday,9,1.50,3,2
+1,"Clan ""A"", <Best>",Sunday,23,6.00,6,1
+`
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestWriteTrendCSV(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteTrendCSV(&b, []Report{exportReport()}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `server_id,server,week,average,peak,samples,change
+1,"Clan ""A"", <Best>",2024-03-04,4.00,8,10,
+1,"Clan ""A"", <Best>",2024-03-11,3.00,5,12,-0.2500
+`
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestWriteSVG(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteSVG(&b, exportReport()); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg" width="730" height="250"`,
+		`font-weight="bold">Clan &#34;A&#34;, &lt;Best&gt;</text>`,
+		`>Mon</text>`,
+		`>23</text>`,
+		// The busiest cell is the darkest, empty cells are grey
+		`fill="#08306b" stroke="#ffffff"><title>Sunday 23:00 avg 6.0 peak 6 (1 samples)</title>`,
+		`fill="#eeeeee" stroke="#ffffff"><title>Monday 00:00 avg 0.0 peak 0 (0 samples)</title>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("SVG is missing %q", want)
+		}
+	}
+	if n := strings.Count(out, "<rect x="); n != 7*24 {
+		t.Errorf("%d cells, want %d", n, 7*24)
+	}
+
+	b.Reset()
+	if err := WriteSVG(&b, Report{ServerID: "7"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), ">Server 7</text>") {
+		t.Error("SVG of an unnamed server has no fallback title")
+	}
+}
+
+func TestShade(t *testing.T) {
+	tests := []struct {
+		value, busiest float64
+		want           string
+	}{
+		{0, 10, "#e8f1fa"},
+		{10, 10, "#08306b"},
+		{5, 10, "#7891b3"},
+		{0, 0, "#e8f1fa"},
+	}
+
+	for _, tt := range tests {
+		if got := shade(tt.value, tt.busiest); got != tt.want {
+			t.Errorf("shade(%v, %v) = %s, want %s", tt.value, tt.busiest, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package population
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/history"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+// Days of the week in heatmap order, Monday first
+var Weekdays = [7]time.Weekday{
+	time.Monday, time.Tuesday, time.Wednesday, time.Thursday,
+	time.Friday, time.Saturday, time.Sunday,
+}
+
+type Cell struct {
+	// Mean player count over the samples in the cell
+	Average float64 `json:"average"`
+	Peak    int     `json:"peak"`
+	Samples int     `json:"samples"`
+}
+
+type Week struct {
+	Start   time.Time `json:"start"`
+	Average float64   `json:"average"`
+	Peak    int       `json:"peak"`
+	Samples int       `json:"samples"`
+	// Change of the average against the week before, as a fraction. Nil
+	// for the first week, after a week without samples or when the week
+	// before averaged zero
+	Change *float64 `json:"change,omitempty"`
+}
+
+type Slot struct {
+	Weekday time.Weekday `json:"weekday"`
+	Hour    int          `json:"hour"`
+	Cell
+}
+
+// Report is the population of one server over the recorded snapshots
+type Report struct {
+	ServerID string `json:"serverId"`
+	Name     string `json:"name"`
+	// Heatmap is indexed by weekday, Monday first, then hour of the day
+	Heatmap [7][24]Cell `json:"heatmap"`
+	Weeks   []Week      `json:"weeks"`
+}
+
+// Build one report per server from recorded snapshots, as returned by
+// history.Store.Snapshots. Hours and weeks are taken in loc, nil meaning
+// UTC. Snapshots of offline servers are skipped so outages do not pull
+// the averages down. Reports are sorted by server id
+func Build(snapshots []history.Snapshot, loc *time.Location) []Report {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	type acc struct {
+		report Report
+		totals [7][24]int
+		weeks  map[time.Time]*Week
+		sums   map[time.Time]int
+	}
+	servers := make(map[string]*acc)
+
+	for _, snap := range snapshots {
+		if !snap.Status.IsOnline {
+			continue
+		}
+
+		a := servers[snap.ServerID]
+		if a == nil {
+			a = &acc{
+				report: Report{ServerID: snap.ServerID},
+				weeks:  make(map[time.Time]*Week),
+				sums:   make(map[time.Time]int),
+			}
+			servers[snap.ServerID] = a
+		}
+		if snap.Status.Name != "" {
+			a.report.Name = server.StripColors(snap.Status.Name)
+		}
+
+		count := int(snap.Status.CurrentPlayers)
+		t := snap.Time.In(loc)
+		day := (int(t.Weekday()) + 6) % 7
+
+		cell := &a.report.Heatmap[day][t.Hour()]
+		cell.Samples++
+		cell.Peak = max(cell.Peak, count)
+		a.totals[day][t.Hour()] += count
+
+		start := weekStart(t)
+		w := a.weeks[start]
+		if w == nil {
+			w = &Week{Start: start}
+			a.weeks[start] = w
+		}
+		w.Samples++
+		w.Peak = max(w.Peak, count)
+		a.sums[start] += count
+	}
+
+	reports := make([]Report, 0, len(servers))
+	for _, a := range servers {
+		for d := range a.report.Heatmap {
+			for h := range a.report.Heatmap[d] {
+				if cell := &a.report.Heatmap[d][h]; cell.Samples > 0 {
+					cell.Average = float64(a.totals[d][h]) / float64(cell.Samples)
+				}
+			}
+		}
+
+		for start, w := range a.weeks {
+			w.Average = float64(a.sums[start]) / float64(w.Samples)
+			a.report.Weeks = append(a.report.Weeks, *w)
+		}
+		slices.SortFunc(a.report.Weeks, func(x, y Week) int { return x.Start.Compare(y.Start) })
+
+		for i := 1; i < len(a.report.Weeks); i++ {
+			prev, cur := a.report.Weeks[i-1], &a.report.Weeks[i]
+			if prev.Start.AddDate(0, 0, 7).Equal(cur.Start) && prev.Average > 0 {
+				change := (cur.Average - prev.Average) / prev.Average
+				cur.Change = &change
+			}
+		}
+
+		reports = append(reports, a.report)
+	}
+
+	// Numeric ids sort by length first so "10" follows "9"
+	slices.SortFunc(reports, func(x, y Report) int {
+		return cmp.Or(cmp.Compare(len(x.ServerID), len(y.ServerID)), strings.Compare(x.ServerID, y.ServerID))
+	})
+	return reports
+}
+
+// Busiest hours of the week by average population, most first
+func (r Report) Busiest(n int) []Slot {
+	var slots []Slot
+	for d := range r.Heatmap {
+		for h, cell := range r.Heatmap[d] {
+			if cell.Samples > 0 {
+				slots = append(slots, Slot{Weekday: Weekdays[d], Hour: h, Cell: cell})
+			}
+		}
+	}
+	slices.SortStableFunc(slots, func(a, b Slot) int {
+		switch {
+		case a.Average > b.Average:
+			return -1
+		case a.Average < b.Average:
+			return 1
+		}
+		return 0
+	})
+	if n > 0 && len(slots) > n {
+		slots = slots[:n]
+	}
+	return slots
+}
+
+// Return midnight on the Monday of t's week in t's location. The day is
+// counted back before building the time, as midnight does not exist on
+// days where the clocks change at midnight and would move to 01:00
+func weekStart(t time.Time) time.Time {
+	monday := t.Day() - (int(t.Weekday())+6)%7
+	return time.Date(t.Year(), t.Month(), monday, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,278 @@
+package population
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/history"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+func snap(serverID string, t time.Time, players int) history.Snapshot {
+	return history.Snapshot{
+		Time:     t,
+		ServerID: serverID,
+		Status:   server.ServerStatus{IsOnline: true, Name: "^1Server " + serverID, CurrentPlayers: int8(players)},
+	}
+}
+
+func location(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func utc(month time.Month, day, hour, minute int) time.Time {
+	return time.Date(2024, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestWeekStart(t *testing.T) {
+	berlin := location(t, "Europe/Berlin")
+	havana := location(t, "America/Havana")
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want time.Time
+	}{
+		{"monday midnight", time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{"sunday night", time.Date(2024, 3, 10, 23, 59, 59, 0, time.UTC), time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{"across a month", time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), time.Date(2024, 2, 26, 0, 0, 0, 0, time.UTC)},
+		{"across a year", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC), time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC)},
+		{"after spring forward", time.Date(2024, 3, 31, 12, 0, 0, 0, berlin), time.Date(2024, 3, 25, 0, 0, 0, 0, berlin)},
+		{"after fall back", time.Date(2024, 10, 27, 12, 0, 0, 0, berlin), time.Date(2024, 10, 21, 0, 0, 0, 0, berlin)},
+		// Havana's clocks skip from 00:00 to 01:00 on Sunday 10 March 2024
+		{"clocks change at midnight", time.Date(2024, 3, 10, 12, 0, 0, 0, havana), time.Date(2024, 3, 4, 0, 0, 0, 0, havana)},
+		{"monday after a midnight change", time.Date(2024, 3, 11, 12, 0, 0, 0, havana), time.Date(2024, 3, 11, 0, 0, 0, 0, havana)},
+	}
+
+	for _, tt := range tests {
+		if got := weekStart(tt.t); !got.Equal(tt.want) {
+			t.Errorf("%s: weekStart(%v) = %v, want %v", tt.name, tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestBuildHeatmap(t *testing.T) {
+	berlin := location(t, "Europe/Berlin")
+
+	tests := []struct {
+		name     string
+		loc      *time.Location
+		snaps    []history.Snapshot
+		day      int // Monday first
+		hour     int
+		want     Cell
+		otherDay int
+	}{
+		{
+			name:  "average and peak",
+			snaps: []history.Snapshot{snap("1", utc(3, 4, 12, 0), 4), snap("1", utc(3, 4, 12, 30), 8), snap("1", utc(3, 11, 12, 15), 3)},
+			day:   0, hour: 12,
+			want: Cell{Average: 5, Peak: 8, Samples: 3},
+		},
+		{
+			name:  "sunday",
+			snaps: []history.Snapshot{snap("1", utc(3, 10, 23, 59), 2)},
+			day:   6, hour: 23,
+			want: Cell{Average: 2, Peak: 2, Samples: 1},
+		},
+		{
+			name:  "offline snapshots are skipped",
+			snaps: []history.Snapshot{snap("1", utc(3, 4, 12, 0), 6), {Time: utc(3, 4, 12, 30), ServerID: "1"}},
+			day:   0, hour: 12,
+			want: Cell{Average: 6, Peak: 6, Samples: 1},
+		},
+		{
+			name:  "taken in the location",
+			loc:   berlin,
+			snaps: []history.Snapshot{snap("1", utc(3, 10, 23, 30), 5)},
+			day:   0, hour: 0,
+			want: Cell{Average: 5, Peak: 5, Samples: 1},
+		},
+		{
+			name: "hour skipped by spring forward",
+			loc:  berlin,
+			// 00:30 and 01:30 UTC are 01:30 CET and 03:30 CEST
+			snaps: []history.Snapshot{snap("1", utc(3, 31, 0, 30), 1), snap("1", utc(3, 31, 1, 30), 3)},
+			day:   6, hour: 2,
+			want: Cell{},
+		},
+		{
+			name: "hour repeated by fall back",
+			loc:  berlin,
+			// 00:30 and 01:30 UTC are 02:30 CEST and 02:30 CET
+			snaps: []history.Snapshot{snap("1", utc(10, 27, 0, 30), 2), snap("1", utc(10, 27, 1, 30), 6)},
+			day:   6, hour: 2,
+			want: Cell{Average: 4, Peak: 6, Samples: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports := Build(tt.snaps, tt.loc)
+			if len(reports) != 1 {
+				t.Fatalf("built %d reports, want 1", len(reports))
+			}
+			if got := reports[0].Heatmap[tt.day][tt.hour]; got != tt.want {
+				t.Errorf("%s %02d:00 = %+v, want %+v", Weekdays[tt.day], tt.hour, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildReports(t *testing.T) {
+	reports := Build([]history.Snapshot{
+		snap("10", utc(3, 4, 12, 0), 1),
+		snap("9", utc(3, 4, 12, 0), 1),
+		snap("2", utc(3, 4, 12, 0), 1),
+		{Time: utc(3, 4, 12, 0), ServerID: "3"},
+	}, nil)
+
+	var ids []string
+	for _, r := range reports {
+		ids = append(ids, r.ServerID)
+	}
+	if len(ids) != 3 || ids[0] != "2" || ids[1] != "9" || ids[2] != "10" {
+		t.Errorf("reports for %q, want 2, 9 and 10", ids)
+	}
+	if reports[0].Name != "Server 2" {
+		t.Errorf("name %q, want the name without colors", reports[0].Name)
+	}
+}
+
+func TestBuildWeeks(t *testing.T) {
+	berlin := location(t, "Europe/Berlin")
+	havana := location(t, "America/Havana")
+	pct := func(f float64) *float64 { return &f }
+
+	tests := []struct {
+		name  string
+		loc   *time.Location
+		snaps []history.Snapshot
+		want  []Week
+	}{
+		{
+			name: "week boundary",
+			snaps: []history.Snapshot{
+				snap("1", utc(3, 10, 23, 59), 4),
+				snap("1", utc(3, 11, 0, 0), 6),
+			},
+			want: []Week{
+				{Start: utc(3, 4, 0, 0), Average: 4, Peak: 4, Samples: 1},
+				{Start: utc(3, 11, 0, 0), Average: 6, Peak: 6, Samples: 1, Change: pct(0.5)},
+			},
+		},
+		{
+			name: "gap between weeks",
+			snaps: []history.Snapshot{
+				snap("1", utc(3, 4, 12, 0), 4),
+				snap("1", utc(3, 18, 12, 0), 2),
+				snap("1", utc(3, 25, 12, 0), 1),
+			},
+			want: []Week{
+				{Start: utc(3, 4, 0, 0), Average: 4, Peak: 4, Samples: 1},
+				{Start: utc(3, 18, 0, 0), Average: 2, Peak: 2, Samples: 1},
+				{Start: utc(3, 25, 0, 0), Average: 1, Peak: 1, Samples: 1, Change: pct(-0.5)},
+			},
+		},
+		{
+			name: "empty week before",
+			snaps: []history.Snapshot{
+				snap("1", utc(3, 4, 12, 0), 0),
+				snap("1", utc(3, 11, 12, 0), 3),
+			},
+			want: []Week{
+				{Start: utc(3, 4, 0, 0), Average: 0, Peak: 0, Samples: 1},
+				{Start: utc(3, 11, 0, 0), Average: 3, Peak: 3, Samples: 1},
+			},
+		},
+		{
+			name: "week shortened by spring forward",
+			loc:  berlin,
+			snaps: []history.Snapshot{
+				snap("1", time.Date(2024, 3, 25, 0, 0, 0, 0, berlin), 2),
+				snap("1", time.Date(2024, 3, 31, 23, 0, 0, 0, berlin), 4),
+				snap("1", time.Date(2024, 4, 1, 0, 0, 0, 0, berlin), 6),
+			},
+			want: []Week{
+				{Start: time.Date(2024, 3, 25, 0, 0, 0, 0, berlin), Average: 3, Peak: 4, Samples: 2},
+				{Start: time.Date(2024, 4, 1, 0, 0, 0, 0, berlin), Average: 6, Peak: 6, Samples: 1, Change: pct(1)},
+			},
+		},
+		{
+			name: "clocks change at midnight",
+			loc:  havana,
+			snaps: []history.Snapshot{
+				snap("1", time.Date(2024, 3, 5, 12, 0, 0, 0, havana), 2),
+				snap("1", time.Date(2024, 3, 10, 12, 0, 0, 0, havana), 4),
+				snap("1", time.Date(2024, 3, 11, 12, 0, 0, 0, havana), 6),
+			},
+			want: []Week{
+				{Start: time.Date(2024, 3, 4, 0, 0, 0, 0, havana), Average: 3, Peak: 4, Samples: 2},
+				{Start: time.Date(2024, 3, 11, 0, 0, 0, 0, havana), Average: 6, Peak: 6, Samples: 1, Change: pct(1)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports := Build(tt.snaps, tt.loc)
+			if len(reports) != 1 {
+				t.Fatalf("built %d reports, want 1", len(reports))
+			}
+
+			got := reports[0].Weeks
+			if len(got) != len(tt.want) {
+				t.Fatalf("weeks %+v, want %+v", got, tt.want)
+			}
+			for i, w := range tt.want {
+				g := got[i]
+				if !g.Start.Equal(w.Start) || g.Average != w.Average || g.Peak != w.Peak || g.Samples != w.Samples ||
+					(g.Change == nil) != (w.Change == nil) || g.Change != nil && *g.Change != *w.Change {
+					t.Errorf("week %d = %+v, want %+v", i, g, w)
+				}
+			}
+		})
+	}
+}
+
+func TestBusiest(t *testing.T) {
+	var r Report
+	r.Heatmap[0][10] = Cell{Average: 2, Samples: 1}
+	r.Heatmap[2][20] = Cell{Average: 7, Samples: 1}
+	r.Heatmap[4][21] = Cell{Average: 7, Samples: 1}
+	r.Heatmap[6][0] = Cell{Average: 3, Samples: 1}
+
+	want := []Slot{
+		{time.Wednesday, 20, r.Heatmap[2][20]},
+		{time.Friday, 21, r.Heatmap[4][21]},
+		{time.Sunday, 0, r.Heatmap[6][0]},
+		{time.Monday, 10, r.Heatmap[0][10]},
+	}
+
+	tests := []struct {
+		n    int
+		want []Slot
+	}{
+		{0, want},
+		{10, want},
+		{2, want[:2]},
+	}
+
+	for _, tt := range tests {
+		got := r.Busiest(tt.n)
+		if len(got) != len(tt.want) {
+			t.Fatalf("Busiest(%d) = %+v, want %+v", tt.n, got, tt.want)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Busiest(%d)[%d] = %+v, want %+v", tt.n, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package recorder
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// Matcher reports whether a live request matches a recorded one. The
+// recorded request is redacted, so matchers redact the live request the
+// same way before comparing
+type Matcher func(req *http.Request, body string, rec Request) bool
+
+func MatchMethod(req *http.Request, body string, rec Request) bool {
+	return req.Method == rec.Method
+}
+
+func MatchPath(req *http.Request, body string, rec Request) bool {
+	return redactPath(req.URL.Path) == rec.Path
+}
+
+// Compare query strings ignoring parameter order
+func MatchQuery(req *http.Request, body string, rec Request) bool {
+	recorded, err := url.ParseQuery(rec.Query)
+	if err != nil {
+		return false
+	}
+
+	live, err := url.ParseQuery(redactQuery(req.URL.RawQuery))
+	if err != nil {
+		return false
+	}
+	if len(live) != len(recorded) {
+		return false
+	}
+
+	for key, values := range live {
+		other := recorded[key]
+		if len(values) != len(other) {
+			return false
+		}
+		for i := range values {
+			if values[i] != other[i] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func MatchBody(req *http.Request, body string, rec Request) bool {
+	return redactBody(body) == rec.Body
+}
+
+// Combine matchers so that all of them must match
+func MatchAll(matchers ...Matcher) Matcher {
+	return func(req *http.Request, body string, rec Request) bool {
+		for _, m := range matchers {
+			if !m(req, body, rec) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Matcher used when none is configured
+var DefaultMatcher = MatchAll(MatchMethod, MatchPath, MatchQuery, MatchBody)
@@ -0,0 +1,161 @@
+package recorder
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// A webfront that echoes the client address it was asked about
+var webfront = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"Set-Cookie":   {".AspNetCore.Cookies=session-secret"},
+			"X-Forwarded":  {"for 10.0.0.5"},
+			"Content-Type": {"text/plain"},
+		},
+		Body:    io.NopCloser(strings.NewReader("player 10.0.0.5 joined")),
+		Request: req,
+	}, nil
+})
+
+func request(t *testing.T) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, "http://127.0.0.1:1624/api/client/10.0.0.5?b=2&ip=10.0.0.5", strings.NewReader("target=10.0.0.5"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Cookie", ".AspNetCore.Cookies=session-secret")
+	req.Header.Set("Authorization", "Bearer token-secret")
+	return req
+}
+
+func do(t *testing.T, rt http.RoundTripper) string {
+	t.Helper()
+	res, err := (&http.Client{Transport: rt}).Do(request(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	rec, err := New(path, Record, webfront)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := do(t, rec); got != "player 10.0.0.5 joined" {
+		t.Errorf("recording returned %q, want the live response", got)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"10.0.0.5", "session-secret", "token-secret"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %q:\n%s", secret, data)
+		}
+	}
+
+	player, err := New(path, Replay, roundTripFunc(func(*http.Request) (*http.Response, error) {
+		t.Fatal("replay used the network")
+		return nil, nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := player.Remaining(); n != 1 {
+		t.Fatalf("%d interactions loaded, want 1", n)
+	}
+	if got := do(t, player); got != "player [REDACTED] joined" {
+		t.Errorf("replay returned %q", got)
+	}
+	if n := player.Remaining(); n != 0 {
+		t.Errorf("%d interactions left after replay, want 0", n)
+	}
+	if _, err := (&http.Client{Transport: player}).Do(request(t)); err == nil {
+		t.Error("an interaction was replayed twice")
+	}
+}
+
+func TestRedactHeader(t *testing.T) {
+	got := redactHeader(http.Header{
+		"Cookie":        {"a=b"},
+		"Set-Cookie":    {"a=b", "c=d"},
+		"Authorization": {"Basic secret"},
+		"X-Real-Ip":     {"192.168.1.20"},
+		"Accept":        {"text/html"},
+	})
+
+	want := map[string]string{
+		"Cookie":        "[REDACTED]",
+		"Set-Cookie":    "[REDACTED]",
+		"Authorization": "[REDACTED]",
+		"X-Real-Ip":     "[REDACTED]",
+		"Accept":        "text/html",
+	}
+	for key, value := range want {
+		if values := got[key]; len(values) != 1 || values[0] != value {
+			t.Errorf("%s = %q, want %q", key, values, value)
+		}
+	}
+}
+
+func TestMatchers(t *testing.T) {
+	rec := Request{Method: "GET", Path: "/api/client/[REDACTED]", Query: "b=2&ip=[REDACTED]&a=1", Body: "x=[REDACTED]"}
+
+	tests := []struct {
+		name    string
+		matcher Matcher
+		method  string
+		url     string
+		body    string
+		want    bool
+	}{
+		{"method", MatchMethod, "GET", "/", "", true},
+		{"other method", MatchMethod, "POST", "/", "", false},
+		{"redacted path", MatchPath, "GET", "/api/client/1.2.3.4", "", true},
+		{"other path", MatchPath, "GET", "/api/client/5", "", false},
+		{"query in any order", MatchQuery, "GET", "/?a=1&ip=1.2.3.4&b=2", "", true},
+		{"query with another value", MatchQuery, "GET", "/?a=1&ip=1.2.3.4&b=3", "", false},
+		{"query with an extra key", MatchQuery, "GET", "/?a=1&ip=1.2.3.4&b=2&c=3", "", false},
+		{"query missing a key", MatchQuery, "GET", "/?a=1&b=2", "", false},
+		{"query with a repeated key", MatchQuery, "GET", "/?a=1&a=1&ip=1.2.3.4&b=2", "", false},
+		{"redacted body", MatchBody, "GET", "/", "x=10.1.1.1", true},
+		{"other body", MatchBody, "GET", "/", "x=y", false},
+		{"all", DefaultMatcher, "GET", "/api/client/1.2.3.4?ip=9.9.9.9&a=1&b=2", "x=8.8.8.8", true},
+		{"all but one", DefaultMatcher, "POST", "/api/client/1.2.3.4?ip=9.9.9.9&a=1&b=2", "x=8.8.8.8", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, "http://webfront"+tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.matcher(req, tt.body, rec); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
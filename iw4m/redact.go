@@ -0,0 +1,15 @@
+package iw4m
+
+import "regexp"
+
+// Placeholder written in place of sensitive values
+const Redacted = "[REDACTED]"
+
+var ipPattern = regexp.MustCompile(
+	`\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b`,
+)
+
+// Replace every IPv4 address in s with a placeholder
+func RedactIPs(s string) string {
+	return ipPattern.ReplaceAllString(s, Redacted)
+}
@@ -0,0 +1,132 @@
+package reserved
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/events"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+// Backend is the part of the server API reserved slots need
+type Backend interface {
+	ServerStatus(serverID string) (*server.ServerStatus, error)
+	Kick(serverID, target, reason string) error
+}
+
+type Config struct {
+	// Levels that hold a reserved slot, default Trusted and above
+	Levels []string
+	// Servers to enforce on, empty enforces on every server
+	Servers []string
+	// Number of slots kept free for reserved players, default 1. A full
+	// game server rejects joining players before any join event is seen,
+	// so a reserved player can only get in through a slot kept free. When
+	// one takes it, a player without a reserved slot is kicked to free it
+	// again. Public players can still take the free slots, after which
+	// reserved players are turned away until someone leaves
+	KeepFree int
+	// Kick reason shown to the removed player
+	Message string
+	// Called after a player was kicked to make room, nil ignores it
+	OnKick func(serverID string, kicked, joined events.Player)
+	// Called when making room fails, nil ignores errors
+	OnError func(serverID string, err error)
+}
+
+// Slots keeps slots free for reserved players by kicking the lowest
+// priority player whenever a reserved player takes one of them
+type Slots struct {
+	backend Backend
+	cfg     Config
+}
+
+// Create new reserved slot enforcement
+func New(backend Backend, cfg Config) *Slots {
+	if len(cfg.Levels) == 0 {
+		trusted := server.LevelRank("Trusted")
+		cfg.Levels = slices.Clone(server.StockLevels[trusted:])
+	}
+	if cfg.KeepFree <= 0 {
+		cfg.KeepFree = 1
+	}
+	if cfg.Message == "" {
+		cfg.Message = "Slot reserved for a VIP, please rejoin later"
+	}
+
+	return &Slots{backend: backend, cfg: cfg}
+}
+
+// Handle events from an events.Watcher
+func (s *Slots) Handle(ev events.Event) {
+	if ev.Type != events.PlayerJoin || ev.Player == nil || !s.Reserved(ev.Player.Level) {
+		return
+	}
+	if len(s.cfg.Servers) > 0 && !slices.Contains(s.cfg.Servers, ev.ServerID) {
+		return
+	}
+
+	if err := s.makeRoom(ev.ServerID, *ev.Player); err != nil && s.cfg.OnError != nil {
+		s.cfg.OnError(ev.ServerID, err)
+	}
+}
+
+// Report whether a level holds a reserved slot
+func (s *Slots) Reserved(level string) bool {
+	return slices.ContainsFunc(s.cfg.Levels, func(l string) bool { return server.SameLevel(l, level) })
+}
+
+func (s *Slots) makeRoom(serverID string, joined events.Player) error {
+	status, err := s.backend.ServerStatus(serverID)
+	if err != nil {
+		return err
+	}
+
+	// The count includes the reserved player who just joined
+	if free := int(status.MaxPlayers) - int(status.CurrentPlayers); free >= s.cfg.KeepFree {
+		return nil
+	}
+
+	victim, ok := s.Victim(status.Players)
+	if !ok {
+		return fmt.Errorf("server %s is full of reserved players", serverID)
+	}
+
+	if err := s.backend.Kick(serverID, server.BySlot(victim.ClientNumber), s.cfg.Message); err != nil {
+		return err
+	}
+
+	if s.cfg.OnKick != nil {
+		s.cfg.OnKick(serverID, events.Player{
+			Name:           victim.Name,
+			ClientNumber:   victim.ClientNumber,
+			Level:          victim.Level,
+			Ping:           victim.Ping,
+			Score:          victim.Score,
+			ConnectionTime: victim.ConnectionTime,
+		}, joined)
+	}
+	return nil
+}
+
+// Pick the player to remove: the lowest level among players without a
+// reserved slot, then the one connected for the shortest time
+func (s *Slots) Victim(players []server.PlayerStatus) (server.PlayerStatus, bool) {
+	var candidates []server.PlayerStatus
+	for _, p := range players {
+		if !s.Reserved(p.Level) {
+			candidates = append(candidates, p)
+		}
+	}
+	if len(candidates) == 0 {
+		return server.PlayerStatus{}, false
+	}
+
+	victim := slices.MinFunc(candidates, func(a, b server.PlayerStatus) int {
+		if d := server.LevelRank(a.Level) - server.LevelRank(b.Level); d != 0 {
+			return d
+		}
+		return a.ConnectionTime - b.ConnectionTime
+	})
+	return victim, true
+}
@@ -0,0 +1,69 @@
+package reserved
+
+import (
+	"testing"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/events"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+type fakeBackend struct {
+	status *server.ServerStatus
+	kicked []string
+}
+
+func (f *fakeBackend) ServerStatus(serverID string) (*server.ServerStatus, error) {
+	return f.status, nil
+}
+
+func (f *fakeBackend) Kick(serverID, target, reason string) error {
+	f.kicked = append(f.kicked, target)
+	return nil
+}
+
+func TestMakeRoom(t *testing.T) {
+	players := []server.PlayerStatus{
+		{Name: "vip", ClientNumber: 0, Level: "Trusted", ConnectionTime: 10},
+		{Name: "old", ClientNumber: 1, Level: "User", ConnectionTime: 500},
+		{Name: "new", ClientNumber: 2, Level: "User", ConnectionTime: 20},
+		{Name: "admin", ClientNumber: 3, Level: "Administrator", ConnectionTime: 5},
+	}
+
+	tests := []struct {
+		name     string
+		keepFree int
+		current  int8
+		max      int8
+		want     string
+	}{
+		{"free slots left", 1, 4, 6, ""},
+		{"took the last free slot", 0, 4, 4, "2"},
+		{"took one of two kept slots", 2, 4, 5, "2"},
+		{"two kept slots still free", 2, 4, 6, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &fakeBackend{status: &server.ServerStatus{
+				CurrentPlayers: tt.current,
+				MaxPlayers:     tt.max,
+				Players:        players,
+			}}
+			slots := New(backend, Config{KeepFree: tt.keepFree})
+
+			slots.Handle(events.Event{
+				Type:     events.PlayerJoin,
+				ServerID: "1",
+				Player:   &events.Player{Name: "vip", Level: "Trusted"},
+			})
+
+			got := ""
+			if len(backend.kicked) > 0 {
+				got = backend.kicked[0]
+			}
+			if got != tt.want || len(backend.kicked) > 1 {
+				t.Errorf("kicked %q, want %q", backend.kicked, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package roster
+
+import "time"
+
+// Roster is the desired set of privileged clients
+type Roster struct {
+	// Members maps client ids to their desired level
+	Members map[string]string `yaml:"members" json:"members"`
+	// Prune demotes privileged clients that are not in the roster
+	Prune bool `yaml:"prune" json:"prune"`
+	// Default is the level pruned clients are demoted to
+	Default string `yaml:"default" json:"default"`
+	// Protected levels are never changed, whatever the roster says
+	Protected []string `yaml:"protected" json:"protected"`
+}
+
+type Action string
+
+const (
+	Promote Action = "promote"
+	Demote  Action = "demote"
+	Change  Action = "change"
+)
+
+type Step struct {
+	Action   Action `json:"action"`
+	ClientID string `json:"clientId"`
+	Name     string `json:"name,omitempty"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Reason   string `json:"reason"`
+}
+
+type Plan struct {
+	Created time.Time `json:"created"`
+	Steps   []Step    `json:"steps"`
+	// Skipped lists roster entries that were left alone, with the reason
+	Skipped []Step `json:"skipped,omitempty"`
+}
+
+type AuditEntry struct {
+	Time     time.Time `json:"time"`
+	ClientID string    `json:"clientId"`
+	Name     string    `json:"name,omitempty"`
+	From     string    `json:"from"`
+	To       string    `json:"to"`
+	Result   string    `json:"result"`
+	Error    string    `json:"error,omitempty"`
+}
@@ -0,0 +1,251 @@
+package roster
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+	"gopkg.in/yaml.v3"
+)
+
+// Backend is the part of the server API the roster needs
+type Backend interface {
+	Admins(role string, count int) ([]server.Admin, error)
+	server.LevelManager
+}
+
+// ErrStale is returned by Apply when a client's level changed since the plan
+// was made
+var ErrStale = errors.New("plan is out of date")
+
+// Load a roster from a YAML or JSON file
+func Load(path string) (*Roster, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read roster: %w", err)
+	}
+
+	// JSON is valid YAML, so a single decoder handles both formats
+	var r Roster
+	if err := yaml.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse roster: %w", err)
+	}
+
+	if r.Default == "" {
+		r.Default = "User"
+	}
+	if r.Protected == nil {
+		r.Protected = []string{"Creator"}
+	}
+	return &r, nil
+}
+
+// Compare the roster with the privileged clients on the webfront and return
+// the minimal set of level changes needed to match it
+func (r *Roster) Plan(b Backend) (*Plan, error) {
+	admins, err := b.Admins("all", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]server.Admin, len(admins))
+	for _, a := range admins {
+		if a.ClientId != "" {
+			current[a.ClientId] = a
+		}
+	}
+
+	plan := &Plan{Created: time.Now().UTC()}
+
+	for _, id := range sortedKeys(r.Members) {
+		want := r.Members[id]
+		var have, name string
+		if admin, ok := current[id]; ok {
+			have, name = admin.Role, admin.Name
+		} else if level, err := b.Level(id); err != nil {
+			// Without the current level a change cannot be ruled out
+			plan.Skipped = append(plan.Skipped, Step{
+				ClientID: id,
+				To:       want,
+				Reason:   fmt.Sprintf("failed to read level: %v", err),
+			})
+			continue
+		} else {
+			have = level
+		}
+
+		step := Step{ClientID: id, Name: name, From: have, To: want}
+		switch {
+		case server.SameLevel(have, want):
+			continue
+		case r.protected(have) || r.protected(want):
+			step.Reason = "protected level"
+			plan.Skipped = append(plan.Skipped, step)
+			continue
+		}
+
+		step.Action = Change
+		step.Reason = "roster level differs"
+		plan.Steps = append(plan.Steps, step)
+	}
+
+	if r.Prune {
+		ids := make([]string, 0, len(current))
+		for id := range current {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			if _, ok := r.Members[id]; ok {
+				continue
+			}
+
+			admin := current[id]
+			step := Step{ClientID: id, Name: admin.Name, From: admin.Role, To: r.Default}
+			if r.protected(admin.Role) {
+				step.Reason = "protected level"
+				plan.Skipped = append(plan.Skipped, step)
+				continue
+			}
+
+			step.Action = Demote
+			step.Reason = "not in roster"
+			plan.Steps = append(plan.Steps, step)
+		}
+	}
+
+	return plan, r.classify(b, plan)
+}
+
+// Apply every step of the plan, writing one audit entry per step to audit.
+// A nil audit writer disables the audit trail. The current levels are read
+// first and nothing is applied when any of them no longer matches the plan,
+// as with a plan saved earlier. Applying stops at the first failed step
+func (p *Plan) Apply(b Backend, audit io.Writer) error {
+	if err := p.check(b); err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(io.Discard)
+	if audit != nil {
+		enc = json.NewEncoder(audit)
+	}
+
+	for _, step := range p.Steps {
+		entry := AuditEntry{
+			Time:     time.Now().UTC(),
+			ClientID: step.ClientID,
+			Name:     step.Name,
+			From:     step.From,
+			To:       step.To,
+			Result:   "applied",
+		}
+
+		_, err := b.SetLevel(step.ClientID, step.To)
+		if err != nil {
+			entry.Result = "failed"
+			entry.Error = err.Error()
+		}
+
+		if encErr := enc.Encode(entry); encErr != nil {
+			return fmt.Errorf("failed to write audit entry: %w", encErr)
+		}
+		if err != nil {
+			return fmt.Errorf("client %s: %w", step.ClientID, err)
+		}
+	}
+	return nil
+}
+
+// Report every step whose client is no longer at the level it started from
+func (p *Plan) check(b Backend) error {
+	var errs []error
+	for _, step := range p.Steps {
+		level, err := b.Level(step.ClientID)
+		if err != nil {
+			return fmt.Errorf("client %s: failed to read level: %w", step.ClientID, err)
+		}
+		if !server.SameLevel(level, step.From) {
+			errs = append(errs, fmt.Errorf("%w: client %s is %s, the plan expects %s", ErrStale, step.ClientID, level, step.From))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Write a human readable summary of the plan
+func (p *Plan) Print(w io.Writer) {
+	if len(p.Steps) == 0 {
+		fmt.Fprintln(w, "No changes. The webfront matches the roster.")
+	}
+
+	for _, step := range p.Steps {
+		sign := "~"
+		switch step.Action {
+		case Promote:
+			sign = "+"
+		case Demote:
+			sign = "-"
+		}
+		fmt.Fprintf(w, "  %s %-7s %-10s %-20s %s -> %s (%s)\n",
+			sign, step.Action, step.ClientID, step.Name, step.From, step.To, step.Reason)
+	}
+
+	for _, step := range p.Skipped {
+		fmt.Fprintf(w, "  ! skip    %-10s %-20s %s -> %s (%s)\n",
+			step.ClientID, step.Name, step.From, step.To, step.Reason)
+	}
+
+	if len(p.Steps) > 0 {
+		fmt.Fprintf(w, "\nPlan: %d to change, %d skipped.\n", len(p.Steps), len(p.Skipped))
+	}
+}
+
+// Turn generic changes into promotions or demotions using the webfront's
+// level order
+func (r *Roster) classify(b Backend, plan *Plan) error {
+	var order []string
+	if lister, ok := b.(interface{ StockRoles() ([]string, error) }); ok {
+		roles, err := lister.StockRoles()
+		if err != nil {
+			return err
+		}
+		order = roles
+	}
+
+	rank := func(level string) int {
+		return slices.IndexFunc(order, func(l string) bool { return server.SameLevel(l, level) })
+	}
+
+	for i, step := range plan.Steps {
+		from, to := rank(step.From), rank(step.To)
+		if from == -1 || to == -1 {
+			continue
+		}
+		if to > from {
+			plan.Steps[i].Action = Promote
+		} else {
+			plan.Steps[i].Action = Demote
+		}
+	}
+	return nil
+}
+
+func (r *Roster) protected(level string) bool {
+	return slices.ContainsFunc(r.Protected, func(p string) bool { return server.SameLevel(p, level) })
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,244 @@
+package roster
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/mock"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+// A webfront with an owner, two admins and a few regular clients
+func webfront() *mock.Server {
+	backend := mock.NewServer()
+	backend.StockRoleList = slices.Clone(server.StockLevels)
+	backend.AdminList = []server.Admin{
+		{Name: "owner", ClientId: "1", Role: "Creator"},
+		{Name: "alice", ClientId: "2", Role: "Administrator"},
+		{Name: "bob", ClientId: "3", Role: "Senior Admin"},
+		{Name: "carol", ClientId: "4", Role: "Moderator"},
+	}
+	backend.Levels = map[string]string{
+		"1": "Creator", "2": "Administrator", "3": "SeniorAdmin", "4": "Moderator",
+		"5": "User", "6": "Trusted",
+	}
+	return backend
+}
+
+// Hides StockRoles, so changes cannot be classified
+type unordered struct{ *mock.Server }
+
+func (u unordered) StockRoles() {}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "roster.yaml")
+	jsonPath := filepath.Join(dir, "roster.json")
+	os.WriteFile(yamlPath, []byte("members:\n  \"2\": Moderator\nprune: true\n"), 0o644)
+	os.WriteFile(jsonPath, []byte(`{"members": {"2": "Owner"}, "default": "Trusted", "protected": []}`), 0o644)
+
+	r, err := Load(yamlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Members["2"] != "Moderator" || !r.Prune || r.Default != "User" || !slices.Equal(r.Protected, []string{"Creator"}) {
+		t.Errorf("YAML roster = %+v", r)
+	}
+
+	r, err = Load(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Members["2"] != "Owner" || r.Prune || r.Default != "Trusted" || len(r.Protected) != 0 {
+		t.Errorf("JSON roster = %+v", r)
+	}
+
+	if _, err := Load(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("loaded a missing roster")
+	}
+}
+
+func TestPlan(t *testing.T) {
+	tests := []struct {
+		name    string
+		roster  Roster
+		backend Backend
+		steps   []Step
+		skipped []string
+	}{
+		{
+			name:    "matching roster",
+			roster:  Roster{Members: map[string]string{"2": "administrator", "3": "SeniorAdmin"}},
+			backend: webfront(),
+		},
+		{
+			name:    "promote, demote and add",
+			roster:  Roster{Members: map[string]string{"2": "Owner", "3": "Moderator", "5": "Trusted"}},
+			backend: webfront(),
+			steps: []Step{
+				{Action: Promote, ClientID: "2", Name: "alice", From: "Administrator", To: "Owner", Reason: "roster level differs"},
+				{Action: Demote, ClientID: "3", Name: "bob", From: "Senior Admin", To: "Moderator", Reason: "roster level differs"},
+				{Action: Promote, ClientID: "5", From: "User", To: "Trusted", Reason: "roster level differs"},
+			},
+		},
+		{
+			name:    "unclassified without level order",
+			roster:  Roster{Members: map[string]string{"5": "Trusted"}},
+			backend: unordered{webfront()},
+			steps: []Step{
+				{Action: Change, ClientID: "5", From: "User", To: "Trusted", Reason: "roster level differs"},
+			},
+		},
+		{
+			name:    "protected levels",
+			roster:  Roster{Members: map[string]string{"1": "User", "5": "Creator"}, Protected: []string{"Creator"}},
+			backend: webfront(),
+			skipped: []string{"1", "5"},
+		},
+		{
+			name:    "unknown client",
+			roster:  Roster{Members: map[string]string{"99": "Trusted"}},
+			backend: webfront(),
+			skipped: []string{"99"},
+		},
+		{
+			name:    "prune",
+			roster:  Roster{Members: map[string]string{"2": "Administrator"}, Prune: true, Default: "User", Protected: []string{"Creator"}},
+			backend: webfront(),
+			steps: []Step{
+				{Action: Demote, ClientID: "3", Name: "bob", From: "Senior Admin", To: "User", Reason: "not in roster"},
+				{Action: Demote, ClientID: "4", Name: "carol", From: "Moderator", To: "User", Reason: "not in roster"},
+			},
+			skipped: []string{"1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := tt.roster.Plan(tt.backend)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(plan.Steps, tt.steps) {
+				t.Errorf("steps\n%+v\nwant\n%+v", plan.Steps, tt.steps)
+			}
+			var skipped []string
+			for _, s := range plan.Skipped {
+				skipped = append(skipped, s.ClientID)
+			}
+			if !slices.Equal(skipped, tt.skipped) {
+				t.Errorf("skipped %q, want %q", skipped, tt.skipped)
+			}
+		})
+	}
+}
+
+func TestPlanError(t *testing.T) {
+	backend := webfront()
+	backend.Err = errors.New("offline")
+	if _, err := (&Roster{}).Plan(backend); err == nil {
+		t.Error("planned without the admin list")
+	}
+}
+
+func audit(t *testing.T, b *bytes.Buffer) []AuditEntry {
+	t.Helper()
+	var entries []AuditEntry
+	dec := json.NewDecoder(b)
+	for dec.More() {
+		var e AuditEntry
+		if err := dec.Decode(&e); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestApply(t *testing.T) {
+	backend := webfront()
+	plan, err := (&Roster{Members: map[string]string{"2": "Owner", "5": "Trusted"}}).Plan(backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var trail bytes.Buffer
+	if err := plan.Apply(backend, &trail); err != nil {
+		t.Fatal(err)
+	}
+
+	if backend.Levels["2"] != "Owner" || backend.Levels["5"] != "Trusted" {
+		t.Errorf("levels %v after applying", backend.Levels)
+	}
+	entries := audit(t, &trail)
+	if len(entries) != 2 || entries[0].ClientID != "2" || entries[0].From != "Administrator" || entries[0].Result != "applied" {
+		t.Errorf("audit trail %+v", entries)
+	}
+}
+
+func TestApplyStopsAtFailure(t *testing.T) {
+	backend := webfront()
+	plan := &Plan{Steps: []Step{
+		{ClientID: "5", From: "User", To: "Nobody"},
+		{ClientID: "6", From: "Trusted", To: "User"},
+	}}
+
+	var trail bytes.Buffer
+	if err := plan.Apply(backend, &trail); err == nil {
+		t.Fatal("applied a level that does not exist")
+	}
+
+	if backend.Levels["6"] != "Trusted" {
+		t.Error("applied a step after the failed one")
+	}
+	entries := audit(t, &trail)
+	if len(entries) != 1 || entries[0].Result != "failed" || entries[0].Error == "" {
+		t.Errorf("audit trail %+v, want one failed entry", entries)
+	}
+}
+
+func TestApplyStalePlan(t *testing.T) {
+	backend := webfront()
+	plan, err := (&Roster{Members: map[string]string{"2": "Owner", "5": "Trusted"}}).Plan(backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Someone changed a level between planning and applying
+	backend.Levels["5"] = "Moderator"
+
+	var trail bytes.Buffer
+	err = plan.Apply(backend, &trail)
+	if !errors.Is(err, ErrStale) || !strings.Contains(err.Error(), "client 5 is Moderator") {
+		t.Fatalf("Apply = %v, want a stale plan error for client 5", err)
+	}
+	if backend.Levels["2"] != "Administrator" || backend.Levels["5"] != "Moderator" || trail.Len() > 0 {
+		t.Errorf("applied part of a stale plan: levels %v, audit %q", backend.Levels, trail.String())
+	}
+}
+
+func TestPrint(t *testing.T) {
+	var b bytes.Buffer
+	(&Plan{}).Print(&b)
+	if !strings.Contains(b.String(), "No changes") {
+		t.Errorf("empty plan printed %q", b.String())
+	}
+
+	b.Reset()
+	(&Plan{
+		Steps:   []Step{{Action: Promote, ClientID: "2", Name: "alice", From: "Administrator", To: "Owner", Reason: "roster level differs"}},
+		Skipped: []Step{{ClientID: "1", From: "Creator", To: "User", Reason: "protected level"}},
+	}).Print(&b)
+	for _, want := range []string{"+ promote 2", "Administrator -> Owner", "! skip    1", "Plan: 1 to change, 1 skipped."} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("plan printed %q, missing %q", b.String(), want)
+		}
+	}
+}
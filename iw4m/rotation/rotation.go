@@ -0,0 +1,295 @@
+package rotation
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/catalog"
+	"github.com/Yallamaztar/iw4m-go/iw4m/events"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+// Backend is the part of the server API the scheduler needs
+type Backend interface {
+	ServerStatus(serverID string) (*server.ServerStatus, error)
+	ChangeMap(serverID, name string) (*server.MapChange, error)
+}
+
+type Tier int
+
+const (
+	Small Tier = iota
+	Medium
+	Large
+)
+
+func (t Tier) String() string {
+	switch t {
+	case Small:
+		return "small"
+	case Medium:
+		return "medium"
+	case Large:
+		return "large"
+	}
+	return fmt.Sprintf("tier(%d)", int(t))
+}
+
+// Map pools by population tier. Maps are given by code or name
+type Pools struct {
+	Small  []string `json:"small" yaml:"small"`
+	Medium []string `json:"medium" yaml:"medium"`
+	Large  []string `json:"large" yaml:"large"`
+}
+
+func (p Pools) tier(t Tier) []string {
+	switch t {
+	case Medium:
+		return p.Medium
+	case Large:
+		return p.Large
+	}
+	return p.Small
+}
+
+type ServerConfig struct {
+	ServerID string `json:"serverId" yaml:"serverId"`
+	// Pools by game code, e.g. "IW4"
+	Pools map[string]Pools `json:"pools" yaml:"pools"`
+	// Player counts at which the medium and large pools take over
+	MediumAt int `json:"mediumAt" yaml:"mediumAt"`
+	LargeAt  int `json:"largeAt" yaml:"largeAt"`
+	// Number of recent maps that are not picked again, default 3
+	History int `json:"history" yaml:"history"`
+}
+
+type Config struct {
+	Servers []ServerConfig `json:"servers" yaml:"servers"`
+	// Change the map this often even mid-match, 0 only acts at map end
+	Interval time.Duration `json:"interval" yaml:"interval"`
+	// Called when a change fails, nil ignores errors
+	OnError func(serverID string, err error) `json:"-" yaml:"-"`
+}
+
+// Scheduler picks maps that suit the current population
+type Scheduler struct {
+	backend Backend
+	cfg     Config
+
+	mu      sync.Mutex
+	history map[string][]string
+	// maps the scheduler or someone reporting through Expect changed to,
+	// so the resulting map change event is not mistaken for a map end
+	pending map[string]string
+}
+
+// Create a new Scheduler
+func New(backend Backend, cfg Config) *Scheduler {
+	for i := range cfg.Servers {
+		if cfg.Servers[i].History <= 0 {
+			cfg.Servers[i].History = 3
+		}
+		if cfg.Servers[i].MediumAt <= 0 {
+			cfg.Servers[i].MediumAt = 6
+		}
+		if cfg.Servers[i].LargeAt <= cfg.Servers[i].MediumAt {
+			cfg.Servers[i].LargeAt = cfg.Servers[i].MediumAt + 6
+		}
+	}
+
+	return &Scheduler{
+		backend: backend,
+		cfg:     cfg,
+		history: make(map[string][]string),
+		pending: make(map[string]string),
+	}
+}
+
+// Handle events from an events.Watcher. When a map ends and the server's own
+// rotation loads a map outside the tier for the current population, the
+// scheduler replaces it. The webfront has no way to set the rotation's next
+// map, so the server loads its own pick first and players see a second map
+// load shortly after. Changes the scheduler made, or that were reported with
+// Expect, are kept as they are
+func (s *Scheduler) Handle(ev events.Event) {
+	if ev.Type != events.MapChange {
+		return
+	}
+
+	cfg, ok := s.server(ev.ServerID)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	s.remember(cfg, ev.Map)
+	expected, ok := s.pending[ev.ServerID]
+	delete(s.pending, ev.ServerID)
+	s.mu.Unlock()
+
+	if ok && (expected == "" || expected == ev.Map) {
+		return
+	}
+
+	status, err := s.backend.ServerStatus(ev.ServerID)
+	if err != nil {
+		s.fail(ev.ServerID, err)
+		return
+	}
+
+	pool := s.pool(cfg, status)
+	if slices.ContainsFunc(pool, func(name string) bool { return sameMap(status.Game, name, ev.Map) }) {
+		return
+	}
+
+	// ChangeMap waits for the server to confirm the change, which would
+	// hold up every other handler of the watcher
+	go func() {
+		if err := s.Advance(ev.ServerID); err != nil {
+			s.fail(ev.ServerID, err)
+		}
+	}()
+}
+
+// Report a map change made outside the scheduler, such as by a map vote or
+// an admin, so the map change event it causes is not replaced. Call it before
+// changing the map. An empty code keeps whichever map loads next
+func (s *Scheduler) Expect(serverID, code string) {
+	s.mu.Lock()
+	s.pending[serverID] = code
+	s.mu.Unlock()
+}
+
+// Change maps on every configured server each Interval until ctx is
+// cancelled. Does nothing when Interval is 0
+func (s *Scheduler) Run(ctx context.Context) {
+	if s.cfg.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, cfg := range s.cfg.Servers {
+			if err := s.Advance(cfg.ServerID); err != nil {
+				s.fail(cfg.ServerID, err)
+			}
+		}
+	}
+}
+
+// Change a server to the next map picked for its population
+func (s *Scheduler) Advance(serverID string) error {
+	next, err := s.Next(serverID)
+	if err != nil {
+		return err
+	}
+
+	s.Expect(serverID, next.Code)
+	_, err = s.backend.ChangeMap(serverID, next.Code)
+	return err
+}
+
+// Pick the next map for a server without changing to it
+func (s *Scheduler) Next(serverID string) (catalog.Map, error) {
+	cfg, ok := s.server(serverID)
+	if !ok {
+		return catalog.Map{}, fmt.Errorf("server %s is not configured", serverID)
+	}
+
+	status, err := s.backend.ServerStatus(serverID)
+	if err != nil {
+		return catalog.Map{}, err
+	}
+
+	game, ok := catalog.Lookup(status.Game)
+	if !ok {
+		return catalog.Map{}, fmt.Errorf("unknown game %q", status.Game)
+	}
+
+	var candidates []catalog.Map
+	for _, name := range s.pool(cfg, status) {
+		if m, ok := game.Map(name); ok && m.Code != status.Map.Name {
+			candidates = append(candidates, m)
+		}
+	}
+	if len(candidates) == 0 {
+		return catalog.Map{}, fmt.Errorf("no maps for %d players on server %s", status.CurrentPlayers, serverID)
+	}
+
+	s.mu.Lock()
+	recent := s.history[serverID]
+	s.mu.Unlock()
+
+	fresh := slices.DeleteFunc(slices.Clone(candidates), func(m catalog.Map) bool {
+		return slices.Contains(recent, m.Code)
+	})
+	if len(fresh) > 0 {
+		return fresh[rand.IntN(len(fresh))], nil
+	}
+
+	// Everything was played recently, so take the one played longest ago
+	slices.SortFunc(candidates, func(a, b catalog.Map) int {
+		return slices.Index(recent, a.Code) - slices.Index(recent, b.Code)
+	})
+	return candidates[0], nil
+}
+
+// Return the tier for a player count
+func (c ServerConfig) Tier(players int) Tier {
+	switch {
+	case players >= c.LargeAt:
+		return Large
+	case players >= c.MediumAt:
+		return Medium
+	}
+	return Small
+}
+
+func (s *Scheduler) pool(cfg ServerConfig, status *server.ServerStatus) []string {
+	return cfg.Pools[status.Game].tier(cfg.Tier(int(status.CurrentPlayers)))
+}
+
+// Must be called with s.mu held
+func (s *Scheduler) remember(cfg ServerConfig, code string) {
+	history := append(s.history[cfg.ServerID], code)
+	if len(history) > cfg.History {
+		history = history[len(history)-cfg.History:]
+	}
+	s.history[cfg.ServerID] = history
+}
+
+func (s *Scheduler) server(serverID string) (ServerConfig, bool) {
+	for _, cfg := range s.cfg.Servers {
+		if cfg.ServerID == serverID {
+			return cfg, true
+		}
+	}
+	return ServerConfig{}, false
+}
+
+func (s *Scheduler) fail(serverID string, err error) {
+	if s.cfg.OnError != nil {
+		s.cfg.OnError(serverID, err)
+	}
+}
+
+func sameMap(game, name, code string) bool {
+	if g, ok := catalog.Lookup(game); ok {
+		if m, ok := g.Map(name); ok {
+			return m.Code == code
+		}
+	}
+	return name == code
+}
@@ -0,0 +1,229 @@
+package rotation
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/events"
+	"github.com/Yallamaztar/iw4m-go/iw4m/mock"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+var pools = map[string]Pools{"IW4": {
+	Small:  []string{"Rust"},
+	Medium: []string{"mp_terminal", "Derail"},
+	Large:  []string{"afghan"},
+}}
+
+func newScheduler(players int, current string) (*Scheduler, *mock.Server) {
+	st := server.ServerStatus{ID: 1, Game: "IW4", CurrentPlayers: int8(players)}
+	st.Map.Name = current
+
+	backend := mock.NewServer()
+	backend.Servers = []server.ServerStatus{st}
+	return New(backend, Config{Servers: []ServerConfig{{ServerID: "1", Pools: pools}}}), backend
+}
+
+// Wait for a map command and return it, or "" when none was executed
+func mapCommand(backend *mock.Server, expect bool) string {
+	deadline := time.Now().Add(time.Second)
+	for {
+		for _, e := range backend.Executed() {
+			if strings.HasPrefix(e.Command, "!map ") {
+				return e.Command
+			}
+		}
+		if !expect || time.Now().After(deadline) {
+			return ""
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestTierString(t *testing.T) {
+	tests := []struct {
+		tier Tier
+		want string
+	}{
+		{Small, "small"},
+		{Medium, "medium"},
+		{Large, "large"},
+		{Tier(3), "tier(3)"},
+		{Tier(-1), "tier(-1)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.tier.String(); got != tt.want {
+			t.Errorf("Tier(%d).String() = %q, want %q", int(tt.tier), got, tt.want)
+		}
+	}
+}
+
+func TestTier(t *testing.T) {
+	defaults, _ := newScheduler(0, "")
+	custom := New(nil, Config{Servers: []ServerConfig{{ServerID: "1", MediumAt: 4, LargeAt: 3}}})
+
+	tests := []struct {
+		name    string
+		cfg     ServerConfig
+		players int
+		want    Tier
+	}{
+		{"empty", defaults.cfg.Servers[0], 0, Small},
+		{"below medium", defaults.cfg.Servers[0], 5, Small},
+		{"medium", defaults.cfg.Servers[0], 6, Medium},
+		{"below large", defaults.cfg.Servers[0], 11, Medium},
+		{"large", defaults.cfg.Servers[0], 12, Large},
+		{"large limit under medium", custom.cfg.Servers[0], 9, Medium},
+		{"large limit moved up", custom.cfg.Servers[0], 10, Large},
+	}
+
+	for _, tt := range tests {
+		if got := tt.cfg.Tier(tt.players); got != tt.want {
+			t.Errorf("%s: Tier(%d) = %v, want %v", tt.name, tt.players, got, tt.want)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name    string
+		players int
+		current string
+		want    string
+		err     bool
+	}{
+		{"small pool", 2, "mp_derail", "mp_rust", false},
+		{"medium pool skips the current map", 8, "mp_terminal", "mp_derail", false},
+		{"large pool", 14, "mp_derail", "mp_afghan", false},
+		{"only the current map", 14, "mp_afghan", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newScheduler(tt.players, tt.current)
+
+			m, err := s.Next("1")
+			if (err != nil) != tt.err {
+				t.Fatalf("error %v, want error %v", err, tt.err)
+			}
+			if m.Code != tt.want {
+				t.Errorf("picked %q, want %q", m.Code, tt.want)
+			}
+		})
+	}
+
+	s, _ := newScheduler(2, "mp_derail")
+	if _, err := s.Next("2"); err == nil {
+		t.Error("picked a map for a server that is not configured")
+	}
+}
+
+func TestNextSkipsHistory(t *testing.T) {
+	s, _ := newScheduler(0, "mp_derail")
+	cfg := s.cfg.Servers[0]
+	cfg.Pools = map[string]Pools{"IW4": {Small: []string{"rust", "terminal", "afghan"}}}
+	s.cfg.Servers[0] = cfg
+
+	s.remember(cfg, "mp_rust")
+	s.remember(cfg, "mp_terminal")
+	for range 20 {
+		if m, err := s.Next("1"); err != nil || m.Code != "mp_afghan" {
+			t.Fatalf("picked %q, %v, want the map not played recently", m.Code, err)
+		}
+	}
+
+	// Every map was played recently, so the oldest comes back first
+	s.remember(cfg, "mp_afghan")
+	if m, err := s.Next("1"); err != nil || m.Code != "mp_rust" {
+		t.Errorf("picked %q, %v, want the map played longest ago", m.Code, err)
+	}
+
+	// Only the last History maps are remembered
+	s.remember(cfg, "mp_rust")
+	if m, err := s.Next("1"); err != nil || m.Code != "mp_terminal" {
+		t.Errorf("picked %q, %v, want the map that fell out of the history", m.Code, err)
+	}
+}
+
+func TestHandle(t *testing.T) {
+	tests := []struct {
+		name    string
+		players int
+		loaded  string
+		setup   func(*Scheduler)
+		want    string
+	}{
+		{"map in the pool", 2, "mp_rust", nil, ""},
+		{"map outside the pool", 2, "mp_derail", nil, "!map mp_rust"},
+		{"pool for the new population", 14, "mp_rust", nil, "!map mp_afghan"},
+		{
+			name:    "change made by the scheduler",
+			players: 14,
+			loaded:  "mp_rust",
+			setup:   func(s *Scheduler) { s.Expect("1", "mp_rust") },
+		},
+		{
+			name:    "change made by a vote",
+			players: 2,
+			loaded:  "mp_derail",
+			setup:   func(s *Scheduler) { s.Expect("1", "mp_derail") },
+		},
+		{
+			name:    "rotation started by a vote",
+			players: 2,
+			loaded:  "mp_derail",
+			setup:   func(s *Scheduler) { s.Expect("1", "") },
+		},
+		{
+			name:    "expected another map",
+			players: 2,
+			loaded:  "mp_derail",
+			setup:   func(s *Scheduler) { s.Expect("1", "mp_afghan") },
+			want:    "!map mp_rust",
+		},
+		{
+			name:    "expectation is used once",
+			players: 2,
+			loaded:  "mp_derail",
+			setup: func(s *Scheduler) {
+				s.Expect("1", "mp_derail")
+				s.Handle(events.Event{Type: events.MapChange, ServerID: "1", Map: "mp_derail"})
+			},
+			want: "!map mp_rust",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, backend := newScheduler(tt.players, tt.loaded)
+			if tt.setup != nil {
+				tt.setup(s)
+			}
+
+			s.Handle(events.Event{Type: events.MapChange, ServerID: "1", Map: tt.loaded})
+
+			if got := mapCommand(backend, tt.want != ""); got != tt.want {
+				t.Errorf("map command %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdvanceIsNotReplaced(t *testing.T) {
+	s, backend := newScheduler(2, "mp_derail")
+	if err := s.Advance("1"); err != nil {
+		t.Fatal(err)
+	}
+	backend.Reset()
+
+	// The population grew before the change was seen, so mp_rust is no
+	// longer in the pool but must not be replaced
+	backend.Servers[0].CurrentPlayers = 14
+	s.Handle(events.Event{Type: events.MapChange, ServerID: "1", Map: "mp_rust"})
+
+	if got := mapCommand(backend, false); got != "" {
+		t.Errorf("replaced the scheduler's own change with %q", got)
+	}
+}
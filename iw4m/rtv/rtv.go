@@ -0,0 +1,270 @@
+package rtv
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/catalog"
+	"github.com/Yallamaztar/iw4m-go/iw4m/events"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+// Backend is the part of the server API the vote bot needs
+type Backend interface {
+	Status() ([]server.ServerStatus, error)
+	ServerStatus(serverID string) (*server.ServerStatus, error)
+	SayOn(serverID, message string) error
+	ChangeMap(serverID, name string) (*server.MapChange, error)
+	RotateMap(serverID string) (*server.MapChange, error)
+}
+
+// Population limits a map can be voted for in
+type Range struct {
+	Min int `json:"min" yaml:"min"`
+	// Max of 0 means no upper limit
+	Max int `json:"max" yaml:"max"`
+}
+
+type Config struct {
+	// Server the bot votes on. Chat attributed to another server is
+	// ignored, chat without a server only counts when the webfront has
+	// a single server
+	ServerID string
+	// Fraction of online players that must vote, default 0.6
+	Threshold float64
+	// Minimum number of votes regardless of population, default 2
+	MinVotes int
+	// Time after a map change before a new vote may start, default 5m
+	Cooldown time.Duration
+	// Maps that can be voted for, by code or name. Empty allows every map
+	// in the catalog for the server's game
+	Maps []string
+	// Population limits by map code
+	Restrictions map[string]Range
+	// Called before a passed vote changes the map, with code "" when it
+	// rotates to the next map. Use it to report the change to a
+	// rotation.Scheduler with Expect. nil ignores changes
+	BeforeChange func(serverID, code string)
+}
+
+// Bot runs rock-the-vote and map votes from chat commands
+type Bot struct {
+	backend Backend
+	cfg     Config
+
+	mu         sync.Mutex
+	voters     map[string]string // voter name without colors to map code, "" for !rtv
+	lastChange time.Time
+	changing   bool
+}
+
+// Create a new vote bot
+func New(backend Backend, cfg Config) *Bot {
+	if cfg.Threshold <= 0 || cfg.Threshold > 1 {
+		cfg.Threshold = 0.6
+	}
+	if cfg.MinVotes <= 0 {
+		cfg.MinVotes = 2
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 5 * time.Minute
+	}
+
+	return &Bot{
+		backend: backend,
+		cfg:     cfg,
+		voters:  make(map[string]string),
+	}
+}
+
+// Handle events from an events.Watcher
+func (b *Bot) Handle(ev events.Event) {
+	switch ev.Type {
+	case events.Chat:
+		if ev.ServerID == "" || ev.ServerID == b.cfg.ServerID {
+			b.handleChat(ev.ServerID, ev.Sender, ev.Message)
+		}
+
+	case events.PlayerLeave:
+		if ev.ServerID == b.cfg.ServerID && ev.Player != nil {
+			b.mu.Lock()
+			delete(b.voters, server.StripColors(ev.Player.Name))
+			b.mu.Unlock()
+		}
+
+	case events.MapChange:
+		if ev.ServerID == b.cfg.ServerID {
+			b.mu.Lock()
+			b.voters = make(map[string]string)
+			b.lastChange = ev.Time
+			b.mu.Unlock()
+		}
+	}
+}
+
+func (b *Bot) handleChat(serverID, sender, message string) {
+	fields := strings.Fields(message)
+	if len(fields) == 0 {
+		return
+	}
+
+	command := strings.ToLower(fields[0])
+	if command != "!rtv" && command != "!vote" {
+		return
+	}
+	if serverID == "" && !b.singleServer() {
+		return
+	}
+	sender = server.StripColors(sender)
+
+	switch command {
+	case "!rtv":
+		b.vote(sender, "")
+	case "!vote":
+		if len(fields) < 2 {
+			b.say("Usage: {yellow}!vote <map>")
+			return
+		}
+		b.vote(sender, strings.Join(fields[1:], " "))
+	}
+}
+
+func (b *Bot) vote(sender, mapName string) {
+	status, err := b.backend.ServerStatus(b.cfg.ServerID)
+	if err != nil {
+		return
+	}
+
+	// The player list covers every server on the webfront, so the
+	// population comes from this server's status
+	population := int(status.CurrentPlayers)
+
+	code := ""
+	if mapName != "" {
+		m, err := b.allowed(status.Game, mapName, population)
+		if err != nil {
+			b.say(err.Error())
+			return
+		}
+		code = m.Code
+	}
+
+	b.mu.Lock()
+	if b.changing {
+		b.mu.Unlock()
+		return
+	}
+	if wait := time.Until(b.lastChange.Add(b.cfg.Cooldown)); wait > 0 {
+		b.mu.Unlock()
+		b.say(fmt.Sprintf("Voting opens in {yellow}%s", wait.Round(time.Second)))
+		return
+	}
+
+	b.voters[sender] = code
+	votes := len(b.voters)
+	needed := max(b.cfg.MinVotes, int(math.Ceil(b.cfg.Threshold*float64(population))))
+	winner := b.leader()
+	passed := votes >= needed
+	if passed {
+		b.changing = true
+		b.voters = make(map[string]string)
+	}
+	b.mu.Unlock()
+
+	if !passed {
+		if code != "" {
+			b.say(fmt.Sprintf("%s voted for {green}%s {white}(%d/%d)", sender, catalog.MapName(status.Game, code), votes, needed))
+		} else {
+			b.say(fmt.Sprintf("%s wants to rock the vote {white}(%d/%d)", sender, votes, needed))
+		}
+		return
+	}
+
+	go b.change(status.Game, winner)
+}
+
+// Report whether the webfront has only this server, so chat that could
+// not be attributed to a server must have been sent on it
+func (b *Bot) singleServer() bool {
+	servers, err := b.backend.Status()
+	return err == nil && len(servers) == 1
+}
+
+// Return the map with the most votes, or "" when nobody nominated one.
+// Must be called with b.mu held
+func (b *Bot) leader() string {
+	tally := make(map[string]int)
+	for _, code := range b.voters {
+		if code != "" {
+			tally[code]++
+		}
+	}
+
+	best, bestVotes := "", 0
+	for code, votes := range tally {
+		if votes > bestVotes || votes == bestVotes && code < best {
+			best, bestVotes = code, votes
+		}
+	}
+	return best
+}
+
+func (b *Bot) change(game, code string) {
+	defer func() {
+		b.mu.Lock()
+		b.changing = false
+		b.lastChange = time.Now()
+		b.mu.Unlock()
+	}()
+
+	if b.cfg.BeforeChange != nil {
+		b.cfg.BeforeChange(b.cfg.ServerID, code)
+	}
+
+	var err error
+	if code == "" {
+		b.say("Vote passed! Rotating to the next map")
+		_, err = b.backend.RotateMap(b.cfg.ServerID)
+	} else {
+		b.say(fmt.Sprintf("Vote passed! Changing map to {green}%s", catalog.MapName(game, code)))
+		_, err = b.backend.ChangeMap(b.cfg.ServerID, code)
+	}
+
+	if err != nil {
+		b.say("{red}Map change failed")
+	}
+}
+
+// Check that a map can be voted for with the current population
+func (b *Bot) allowed(game, name string, population int) (catalog.Map, error) {
+	m, err := catalog.ValidateMap(game, name, "")
+	if err != nil {
+		return catalog.Map{}, fmt.Errorf("{red}Unknown map %q", name)
+	}
+
+	if len(b.cfg.Maps) > 0 && !slices.ContainsFunc(b.cfg.Maps, func(allowed string) bool {
+		candidate, err := catalog.ValidateMap(game, allowed, "")
+		return err == nil && candidate.Code == m.Code
+	}) {
+		return catalog.Map{}, fmt.Errorf("{red}%s is not in the vote pool", m.Name)
+	}
+
+	if r, ok := b.cfg.Restrictions[m.Code]; ok {
+		if population < r.Min {
+			return catalog.Map{}, fmt.Errorf("{red}%s needs at least %d players", m.Name, r.Min)
+		}
+		if r.Max > 0 && population > r.Max {
+			return catalog.Map{}, fmt.Errorf("{red}%s allows at most %d players", m.Name, r.Max)
+		}
+	}
+
+	return m, nil
+}
+
+func (b *Bot) say(message string) {
+	b.backend.SayOn(b.cfg.ServerID, server.Colorize(message))
+}
@@ -0,0 +1,267 @@
+package rtv
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/events"
+	"github.com/Yallamaztar/iw4m-go/iw4m/mock"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+func status(id, players int) server.ServerStatus {
+	st := server.ServerStatus{ID: id, Game: "IW4", CurrentPlayers: int8(players)}
+	st.Map.Name = "mp_derail"
+	return st
+}
+
+func chat(serverID, sender, message string) events.Event {
+	return events.Event{Type: events.Chat, ServerID: serverID, Sender: sender, Message: message}
+}
+
+func leave(serverID, name string) events.Event {
+	return events.Event{Type: events.PlayerLeave, ServerID: serverID, Player: &events.Player{Name: name}}
+}
+
+// Wait for the vote bot to switch maps and return the command it used, or
+// "" when no map command was executed
+func mapCommand(backend *mock.Server, expect bool) string {
+	deadline := time.Now().Add(time.Second)
+	for {
+		for _, e := range backend.Executed() {
+			if e.Command == "!maprotate" || strings.HasPrefix(e.Command, "!map ") {
+				return e.Command
+			}
+		}
+		if !expect || time.Now().After(deadline) {
+			return ""
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestVote(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		servers []server.ServerStatus
+		events  []events.Event
+		want    string
+		says    string
+	}{
+		{
+			name:    "threshold reached",
+			cfg:     Config{Threshold: 0.5},
+			servers: []server.ServerStatus{status(1, 4)},
+			events:  []events.Event{chat("1", "alice", "!rtv"), chat("1", "bob", "!RTV")},
+			want:    "!maprotate",
+		},
+		{
+			name:    "below threshold",
+			cfg:     Config{Threshold: 0.75},
+			servers: []server.ServerStatus{status(1, 4)},
+			events:  []events.Event{chat("1", "alice", "!rtv"), chat("1", "bob", "!rtv")},
+			says:    "(2/3)",
+		},
+		{
+			name:    "minimum votes on an empty server",
+			cfg:     Config{Threshold: 0.5, MinVotes: 2},
+			servers: []server.ServerStatus{status(1, 1)},
+			events:  []events.Event{chat("1", "alice", "!rtv")},
+			says:    "(1/2)",
+		},
+		{
+			name:    "colored name votes once",
+			cfg:     Config{Threshold: 0.5},
+			servers: []server.ServerStatus{status(1, 4)},
+			events:  []events.Event{chat("1", "^1bob", "!rtv"), chat("1", "bob", "!rtv")},
+			says:    "(1/2)",
+		},
+		{
+			name:    "leaving drops the vote",
+			cfg:     Config{Threshold: 0.5},
+			servers: []server.ServerStatus{status(1, 4)},
+			events: []events.Event{
+				chat("1", "bob", "!rtv"),
+				leave("1", "^2bob"),
+				chat("1", "alice", "!rtv"),
+			},
+			says: "alice wants to rock the vote ^7(1/2)",
+		},
+		{
+			name:    "leaving another server keeps the vote",
+			cfg:     Config{Threshold: 0.5},
+			servers: []server.ServerStatus{status(1, 4), status(2, 4)},
+			events: []events.Event{
+				chat("1", "bob", "!rtv"),
+				leave("2", "bob"),
+				chat("1", "alice", "!rtv"),
+			},
+			want: "!maprotate",
+		},
+		{
+			name:    "cooldown after a map change",
+			cfg:     Config{Threshold: 0.5, Cooldown: time.Hour},
+			servers: []server.ServerStatus{status(1, 4)},
+			events: []events.Event{
+				{Type: events.MapChange, ServerID: "1", Time: time.Now()},
+				chat("1", "alice", "!rtv"),
+				chat("1", "bob", "!rtv"),
+			},
+			says: "Voting opens in",
+		},
+		{
+			name:    "map change resets votes",
+			cfg:     Config{Threshold: 0.5, Cooldown: time.Nanosecond},
+			servers: []server.ServerStatus{status(1, 4)},
+			events: []events.Event{
+				chat("1", "alice", "!rtv"),
+				{Type: events.MapChange, ServerID: "1", Time: time.Now().Add(-time.Minute)},
+				chat("1", "bob", "!rtv"),
+			},
+			says: "(1/2)",
+		},
+		{
+			name: "map needs more players",
+			cfg: Config{
+				Threshold:    0.5,
+				Restrictions: map[string]Range{"mp_rust": {Min: 6}},
+			},
+			servers: []server.ServerStatus{status(1, 4)},
+			events:  []events.Event{chat("1", "alice", "!vote rust"), chat("1", "bob", "!vote rust")},
+			says:    "Rust needs at least 6 players",
+		},
+		{
+			name: "map allows fewer players",
+			cfg: Config{
+				Threshold:    0.5,
+				Restrictions: map[string]Range{"mp_rust": {Max: 2}},
+			},
+			servers: []server.ServerStatus{status(1, 4)},
+			events:  []events.Event{chat("1", "alice", "!vote rust"), chat("1", "bob", "!vote rust")},
+			says:    "Rust allows at most 2 players",
+		},
+		{
+			name:    "map outside the pool",
+			cfg:     Config{Threshold: 0.5, Maps: []string{"Terminal", "mp_afghan"}},
+			servers: []server.ServerStatus{status(1, 4)},
+			events:  []events.Event{chat("1", "alice", "!vote rust"), chat("1", "bob", "!vote rust")},
+			says:    "Rust is not in the vote pool",
+		},
+		{
+			name:    "unknown map",
+			cfg:     Config{Threshold: 0.5},
+			servers: []server.ServerStatus{status(1, 4)},
+			events:  []events.Event{chat("1", "alice", "!vote nowhere")},
+			says:    `Unknown map "nowhere"`,
+		},
+		{
+			name:    "map in the pool",
+			cfg:     Config{Threshold: 0.5, Maps: []string{"Terminal"}},
+			servers: []server.ServerStatus{status(1, 4)},
+			events:  []events.Event{chat("1", "alice", "!vote terminal"), chat("1", "bob", "!vote mp_terminal")},
+			want:    "!map mp_terminal",
+		},
+		{
+			name:    "most voted map wins",
+			cfg:     Config{Threshold: 0.75},
+			servers: []server.ServerStatus{status(1, 4)},
+			events: []events.Event{
+				chat("1", "alice", "!vote rust"),
+				chat("1", "bob", "!vote terminal"),
+				chat("1", "carol", "!vote terminal"),
+			},
+			want: "!map mp_terminal",
+		},
+		{
+			name:    "tie goes to the first map code",
+			cfg:     Config{Threshold: 0.5},
+			servers: []server.ServerStatus{status(1, 4)},
+			events:  []events.Event{chat("1", "alice", "!vote rust"), chat("1", "bob", "!vote afghan")},
+			want:    "!map mp_afghan",
+		},
+		{
+			name:    "nominations beat plain rtv",
+			cfg:     Config{Threshold: 0.5},
+			servers: []server.ServerStatus{status(1, 4)},
+			events:  []events.Event{chat("1", "alice", "!rtv"), chat("1", "bob", "!vote rust")},
+			want:    "!map mp_rust",
+		},
+		{
+			name:    "chat from another server",
+			cfg:     Config{Threshold: 0.5},
+			servers: []server.ServerStatus{status(1, 4), status(2, 4)},
+			events:  []events.Event{chat("2", "alice", "!rtv"), chat("2", "bob", "!rtv")},
+		},
+		{
+			name:    "unattributed chat with several servers",
+			cfg:     Config{Threshold: 0.5},
+			servers: []server.ServerStatus{status(1, 4), status(2, 4)},
+			events:  []events.Event{chat("", "alice", "!rtv"), chat("", "bob", "!rtv")},
+		},
+		{
+			name:    "unattributed chat with one server",
+			cfg:     Config{Threshold: 0.5},
+			servers: []server.ServerStatus{status(1, 4)},
+			events:  []events.Event{chat("", "alice", "!rtv"), chat("", "bob", "!rtv")},
+			want:    "!maprotate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := mock.NewServer()
+			backend.Servers = tt.servers
+			tt.cfg.ServerID = "1"
+			bot := New(backend, tt.cfg)
+
+			for _, ev := range tt.events {
+				bot.Handle(ev)
+			}
+
+			if got := mapCommand(backend, tt.want != ""); got != tt.want {
+				t.Errorf("map command %q, want %q", got, tt.want)
+			}
+
+			if tt.says != "" {
+				var said []string
+				for _, e := range backend.Executed() {
+					said = append(said, e.Command)
+				}
+				if !strings.Contains(strings.Join(said, "\n"), tt.says) {
+					t.Errorf("said %q, want a line containing %q", said, tt.says)
+				}
+			}
+		})
+	}
+}
+
+func TestBeforeChange(t *testing.T) {
+	backend := mock.NewServer()
+	backend.Servers = []server.ServerStatus{status(1, 2)}
+
+	reported := make(chan string, 1)
+	bot := New(backend, Config{
+		ServerID:  "1",
+		Threshold: 0.5,
+		BeforeChange: func(serverID, code string) {
+			if mapCommand(backend, false) != "" {
+				t.Error("reported the change after making it")
+			}
+			reported <- serverID + ":" + code
+		},
+	})
+
+	bot.Handle(chat("1", "alice", "!vote rust"))
+	bot.Handle(chat("1", "bob", "!vote rust"))
+
+	select {
+	case got := <-reported:
+		if got != "1:mp_rust" {
+			t.Errorf("reported %q, want %q", got, "1:mp_rust")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the change was not reported")
+	}
+}
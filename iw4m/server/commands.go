@@ -0,0 +1,65 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Routes of the webfront console action. ASP.NET Core strips the Async
+// suffix from action names, so current webfronts serve /Console/Execute.
+// The suffixed route is tried when that one is not found
+var consoleRoutes = []string{"/Console/Execute", "/Console/ExecuteAsync"}
+
+// Execute a command on the wrapper's default server
+func (s *Server) ExecuteCommand(command string) ([]string, error) {
+	return s.ExecuteCommandOn(s.iw4m.ServerID, command)
+}
+
+// Execute a command on the given server and return the response lines
+func (s *Server) ExecuteCommandOn(serverID, command string) ([]string, error) {
+	if serverID == "" {
+		return nil, fmt.Errorf("server id is required")
+	}
+	if command == "" {
+		return nil, fmt.Errorf("command is required")
+	}
+
+	var res *http.Response
+	for _, route := range consoleRoutes {
+		endpoint := fmt.Sprintf(
+			"%s?serverId=%s&command=%s",
+			route, url.QueryEscape(serverID), url.QueryEscape(command),
+		)
+
+		var err error
+		res, err = s.iw4m.DoRequest(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode != http.StatusNotFound {
+			break
+		}
+		res.Body.Close()
+	}
+
+	body, err := readBody(res)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode >= 400 {
+		return nil, fmt.Errorf("console returned %s", res.Status)
+	}
+
+	var responses []commandResponse
+	if err := json.Unmarshal(body, &responses); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	lines := make([]string, 0, len(responses))
+	for _, r := range responses {
+		lines = append(lines, r.Response)
+	}
+	return lines, nil
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m"
+	"github.com/Yallamaztar/iw4m-go/iw4m/recorder"
+)
+
+// Create a server replaying the exchanges in a cassette under testdata
+func replay(t *testing.T, cassette string) (*Server, *recorder.Recorder) {
+	t.Helper()
+
+	rec, err := recorder.New("testdata/"+cassette, recorder.Replay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrapper := iw4m.NewWrapper("http://webfront.test", "1", "")
+	rec.Attach(wrapper)
+	return NewServer(wrapper), rec
+}
+
+func TestExecuteCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		cassette string
+	}{
+		{"current webfront", "console.json"},
+		{"webfront keeping the Async suffix", "console_legacy.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, rec := replay(t, tt.cassette)
+
+			lines, err := s.ExecuteCommand("!say hello")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []string{"hello"}; !slices.Equal(lines, want) {
+				t.Errorf("ExecuteCommand = %q, want %q", lines, want)
+			}
+			if n := rec.Remaining(); n != 0 {
+				t.Errorf("%d recorded exchanges not replayed", n)
+			}
+		})
+	}
+}
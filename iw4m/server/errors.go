@@ -0,0 +1,10 @@
+package server
+
+import "errors"
+
+// ErrNotFound is returned when the webfront has no data for a lookup
+var ErrNotFound = errors.New("not found")
+
+// ErrUnverified is returned when a change was submitted but reading it back
+// did not confirm it, so it may or may not have taken effect
+var ErrUnverified = errors.New("change not verified")
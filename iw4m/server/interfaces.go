@@ -0,0 +1,80 @@
+package server
+
+import "time"
+
+// Reader is the read-only surface of an IW4M webfront
+type Reader interface {
+	Status() ([]ServerStatus, error)
+	Info() (*ServerInfo, error)
+	MapName() (string, error)
+	GameMode() (string, error)
+	IW4MVersion() (string, error)
+	LoggedInAs() (string, error)
+	Rules() ([]string, error)
+	Reports() ([]Report, error)
+	Help() (*Help, error)
+	ServerIDs() ([]ServerID, error)
+	ReadChat() ([]Chat, error)
+	FindPlayer(username, xuid string, count, offset, direction int) ([]FindPlayer, error)
+	ListPlayers() ([]Players, error)
+	StockRoles() ([]string, error)
+	Roles() ([]string, error)
+	RecentClients(offset int) ([]RecentClient, error)
+	RecentAuditLog() (*AuditLog, error)
+	AuditLogs(count int) ([]AuditLog, error)
+	Penalties(clientID string) ([]Penalty, error)
+	Admins(role string, count int) ([]Admin, error)
+	TopPlayers(count int) ([]TopPlayer, error)
+}
+
+// Commander executes commands on a game server
+type Commander interface {
+	ExecuteCommand(command string) ([]string, error)
+	ExecuteCommandOn(serverID, command string) ([]string, error)
+}
+
+// LevelManager reads and changes client levels
+type LevelManager interface {
+	Level(clientID string) (string, error)
+	SetLevel(clientID, level string) (*LevelChange, error)
+	Promote(clientID string) (*LevelChange, error)
+	Demote(clientID string) (*LevelChange, error)
+}
+
+// Messenger sends in-game chat messages
+type Messenger interface {
+	Say(message string) error
+	SayOn(serverID, message string) error
+	Tell(target, message string) error
+	TellOn(serverID, target, message string) error
+	Broadcast(message string) error
+}
+
+// MapController changes maps and rotation
+type MapController interface {
+	ServerStatus(serverID string) (*ServerStatus, error)
+	ChangeMap(serverID, name string) (*MapChange, error)
+	RotateMap(serverID string) (*MapChange, error)
+	RestartMap(serverID string) (*MapChange, error)
+	FastRestart(serverID string) (*MapChange, error)
+}
+
+// Punisher warns, kicks and bans clients
+type Punisher interface {
+	Warn(serverID, target, reason string) error
+	Kick(serverID, target, reason string) error
+	TempBan(serverID, target string, duration time.Duration, reason string) error
+	Ban(serverID, target, reason string) error
+}
+
+// API is the full surface implemented by Server
+type API interface {
+	Reader
+	Commander
+	LevelManager
+	Messenger
+	MapController
+	Punisher
+}
+
+var _ API = (*Server)(nil)
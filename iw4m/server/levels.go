@@ -0,0 +1,225 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Routes tried in order when the edit form does not declare an action.
+// ASP.NET Core strips the Async suffix from action names, older webfronts
+// kept it
+var editRoutes = []string{"/Action/Edit", "/Action/EditAsync"}
+
+// Stock IW4M-Admin levels, lowest first
+var StockLevels = []string{
+	"Banned", "Flagged", "User", "Trusted", "Moderator",
+	"Administrator", "SeniorAdmin", "Owner", "Creator",
+}
+
+// Compare level names the way the webfront displays and submits them, e.g.
+// "Senior Admin" and "SeniorAdmin"
+func SameLevel(a, b string) bool {
+	return strings.EqualFold(strings.ReplaceAll(a, " ", ""), strings.ReplaceAll(b, " ", ""))
+}
+
+// Rank a level within StockLevels, ignoring case and spaces. Unknown levels
+// rank as User
+func LevelRank(level string) int {
+	for i, l := range StockLevels {
+		if SameLevel(l, level) {
+			return i
+		}
+	}
+	return 2
+}
+
+type editForm struct {
+	action string
+	fields url.Values
+	levels []string
+}
+
+func (s *Server) readEditForm(clientID string) (*editForm, error) {
+	endpoint := fmt.Sprintf("/Action/editForm/?id=%s&meta=", url.QueryEscape(clientID))
+	doc, err := s.getDoc(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	form := &editForm{fields: url.Values{}}
+
+	formTag := doc.Find("form").First()
+	if action, exists := formTag.Attr("action"); exists && action != "" {
+		form.action = action
+	}
+
+	doc.Find("input[name]").Each(
+		func(i int, input *goquery.Selection) {
+			name := input.AttrOr("name", "")
+			switch input.AttrOr("type", "text") {
+			case "submit", "button":
+				return
+			case "checkbox", "radio":
+				if _, checked := input.Attr("checked"); !checked {
+					return
+				}
+			}
+			form.fields.Add(name, input.AttrOr("value", ""))
+		})
+
+	doc.Find("select[name]").Each(
+		func(i int, sel *goquery.Selection) {
+			name := sel.AttrOr("name", "")
+			options := sel.Find("option")
+
+			selected := options.Filter("[selected]").First()
+			if selected.Length() == 0 {
+				selected = options.First()
+			}
+			form.fields.Set(name, selected.AttrOr("value", strings.TrimSpace(selected.Text())))
+
+			if name == "level" {
+				options.Each(func(j int, option *goquery.Selection) {
+					if value := option.AttrOr("value", ""); value != "" {
+						form.levels = append(form.levels, value)
+					}
+				})
+			}
+		})
+
+	if len(form.levels) == 0 {
+		s.warnMissing(endpoint, "select[name='level']")
+		return nil, fmt.Errorf("level field not found in edit form for client %s", clientID)
+	}
+	if form.fields.Get("id") == "" {
+		form.fields.Set("id", clientID)
+	}
+
+	return form, nil
+}
+
+// Read the current level of a client from its edit form
+func (s *Server) Level(clientID string) (string, error) {
+	form, err := s.readEditForm(clientID)
+	if err != nil {
+		return "", err
+	}
+	return form.fields.Get("level"), nil
+}
+
+// Set the level of a client by submitting its edit form, then re-read the
+// form to verify the change. When the submit fails nothing is returned but
+// the error. When only the verification fails the change is returned with
+// an error wrapping ErrUnverified. Level names are matched with SameLevel
+func (s *Server) SetLevel(clientID, level string) (*LevelChange, error) {
+	form, err := s.readEditForm(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	requested := ""
+	for _, l := range form.levels {
+		if SameLevel(l, level) {
+			requested = l
+			break
+		}
+	}
+	if requested == "" {
+		return nil, fmt.Errorf("level %q: %w", level, ErrNotFound)
+	}
+
+	change := &LevelChange{
+		ClientID:  clientID,
+		Previous:  form.fields.Get("level"),
+		Requested: requested,
+	}
+
+	if change.Previous != requested {
+		form.fields.Set("level", requested)
+		if err := s.submitForm(form.action, form.fields); err != nil {
+			return nil, err
+		}
+	}
+
+	current, err := s.Level(clientID)
+	if err != nil {
+		return change, fmt.Errorf("failed to verify level change: %w: %w", ErrUnverified, err)
+	}
+
+	change.Current = current
+	change.Verified = current == requested
+	if !change.Verified {
+		return change, fmt.Errorf("level of client %s is %q after setting it to %q: %w", clientID, current, requested, ErrUnverified)
+	}
+
+	return change, nil
+}
+
+// Raise a client one level
+func (s *Server) Promote(clientID string) (*LevelChange, error) {
+	return s.shiftLevel(clientID, 1)
+}
+
+// Lower a client one level
+func (s *Server) Demote(clientID string) (*LevelChange, error) {
+	return s.shiftLevel(clientID, -1)
+}
+
+func (s *Server) shiftLevel(clientID string, delta int) (*LevelChange, error) {
+	form, err := s.readEditForm(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	current := form.fields.Get("level")
+	for i, l := range form.levels {
+		if l != current {
+			continue
+		}
+
+		next := i + delta
+		if next < 0 || next >= len(form.levels) {
+			return nil, fmt.Errorf("client %s is already at level %s", clientID, current)
+		}
+		return s.SetLevel(clientID, form.levels[next])
+	}
+
+	return nil, fmt.Errorf("current level %q of client %s is not assignable", current, clientID)
+}
+
+// Submit an edit form to its action, or to the first edit route the
+// webfront serves when the form did not declare one
+func (s *Server) submitForm(action string, fields url.Values) error {
+	routes := editRoutes
+	if action != "" {
+		routes = []string{action}
+	}
+
+	var res *http.Response
+	for _, route := range routes {
+		var err error
+		res, err = s.iw4m.DoPostRequest(route, fields)
+		if err != nil {
+			return err
+		}
+		action = route
+		if res.StatusCode != http.StatusNotFound {
+			break
+		}
+		res.Body.Close()
+	}
+
+	body, err := readBody(res)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode >= 400 {
+		return fmt.Errorf("%s returned %s: %s", action, res.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package server
+
+import "testing"
+
+func TestSetLevelSubmitRejected(t *testing.T) {
+	s, rec := replay(t, "level_rejected.json")
+
+	change, err := s.SetLevel("5", "trusted")
+	if err == nil {
+		t.Fatal("SetLevel succeeded, want the submit error")
+	}
+	if change != nil {
+		t.Errorf("SetLevel returned change %+v for a rejected submit, want nil", change)
+	}
+	if n := rec.Remaining(); n != 0 {
+		t.Errorf("%d recorded exchanges not replayed", n)
+	}
+}
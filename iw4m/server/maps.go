@@ -0,0 +1,24 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/catalog"
+)
+
+// Resolve a user supplied map name such as "Rust" to the engine map code
+// using the catalog for the game. Engine codes of maps missing from the
+// catalog are returned unchanged, so custom maps keep working
+func ResolveMap(game, name string) (string, bool) {
+	if g, ok := catalog.Lookup(game); ok {
+		if m, ok := g.Map(name); ok {
+			return m.Code, true
+		}
+	}
+
+	name = strings.ToLower(strings.TrimSpace(name))
+	if strings.HasPrefix(name, "mp_") || strings.HasPrefix(name, "zm_") {
+		return name, true
+	}
+	return "", false
+}
@@ -0,0 +1,355 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/chatqueue"
+)
+
+// Command prefix used until SetCommandPrefix is called, IW4M-Admin's default
+const DefaultCommandPrefix = "!"
+
+// Chat length used for games without a known limit
+const DefaultChatLimit = 100
+
+// Longest chat line each game displays without truncating, keyed by
+// ServerStatus.Game. The values are conservative so split messages are
+// never cut off by the client
+var ChatLimits = map[string]int{
+	"IW3":  150,
+	"IW4":  150,
+	"IW5":  150,
+	"IW6":  150,
+	"T4":   150,
+	"T5":   100,
+	"T6":   100,
+	"T7":   100,
+	"SHG1": 150,
+	"H1":   150,
+	"L4D2": 100,
+}
+
+var colorCodes = map[string]string{
+	"black":   "^0",
+	"red":     "^1",
+	"green":   "^2",
+	"yellow":  "^3",
+	"blue":    "^4",
+	"cyan":    "^5",
+	"pink":    "^6",
+	"white":   "^7",
+	"default": "^7",
+	"team":    "^8",
+	"grey":    "^9",
+	"gray":    "^9",
+}
+
+// Replace color templates such as {red} or {yellow} with game color codes.
+// Unknown templates are left untouched
+func Colorize(message string) string {
+	for name, code := range colorCodes {
+		message = strings.ReplaceAll(message, "{"+name+"}", code)
+	}
+	return message
+}
+
+// Target a client by its IW4M client id
+func ByClientID(clientID string) string {
+	return "@" + clientID
+}
+
+// Target a connected client by its slot number
+func BySlot(clientNumber int) string {
+	return strconv.Itoa(clientNumber)
+}
+
+// Use prefix in front of every command the wrapper sends, for servers that
+// changed IW4M-Admin's default
+func (s *Server) SetCommandPrefix(prefix string) {
+	s.mu.Lock()
+	s.prefix = prefix
+	s.mu.Unlock()
+}
+
+// Detect the command prefix from the audit log and use it from now on
+func (s *Server) UseDetectedCommandPrefix() (string, error) {
+	prefix, err := s.DetectCommandPrefix()
+	if err != nil {
+		return "", err
+	}
+	s.SetCommandPrefix(prefix)
+	return prefix, nil
+}
+
+func (s *Server) commandPrefix() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.prefix == "" {
+		return DefaultCommandPrefix
+	}
+	return s.prefix
+}
+
+// Replace the chat queue with one using opts and return it. Messages still
+// waiting in the previous queue are discarded. Chat is delivered
+// asynchronously, so chat methods only report queueing errors such as
+// chatqueue.ErrQueueFull
+func (s *Server) UseQueue(opts chatqueue.Options) *chatqueue.Queue {
+	q := s.newQueue(opts)
+
+	s.mu.Lock()
+	previous := s.queue
+	s.queue = q
+	s.mu.Unlock()
+
+	if previous != nil {
+		previous.Close()
+	}
+	return q
+}
+
+// Return the chat queue, or nil when it was disabled. Use it to check
+// Pending or Full before sending a burst of messages
+func (s *Server) Queue() *chatqueue.Queue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.queue
+}
+
+// Send chat synchronously from now on, without rate limiting, so chat
+// methods report delivery errors. Meant for one-shot tools that exit right
+// after sending. Messages still waiting in the queue are discarded
+func (s *Server) DisableQueue() {
+	s.mu.Lock()
+	previous := s.queue
+	s.queue = nil
+	s.mu.Unlock()
+
+	if previous != nil {
+		previous.Close()
+	}
+}
+
+func (s *Server) newQueue(opts chatqueue.Options) *chatqueue.Queue {
+	return chatqueue.New(func(serverID, command string) error {
+		_, err := s.ExecuteCommandOn(serverID, command)
+		return err
+	}, opts)
+}
+
+// Say a message to everyone on the wrapper's default server
+func (s *Server) Say(message string) error {
+	return s.SayOn(s.iw4m.ServerID, message)
+}
+
+// Say a message to everyone on the given server
+func (s *Server) SayOn(serverID, message string) error {
+	return s.sendChat(serverID, "say", message, chatqueue.Normal)
+}
+
+// Say a message on the given server with a queue priority
+func (s *Server) SayOnPriority(serverID, message string, priority chatqueue.Priority) error {
+	return s.sendChat(serverID, "say", message, priority)
+}
+
+// Send a private message to a client on the wrapper's default server
+func (s *Server) Tell(target, message string) error {
+	return s.TellOn(s.iw4m.ServerID, target, message)
+}
+
+// Send a private message to a client on the given server
+func (s *Server) TellOn(serverID, target, message string) error {
+	return s.TellOnPriority(serverID, target, message, chatqueue.Normal)
+}
+
+// Send a private message on the given server with a queue priority
+func (s *Server) TellOnPriority(serverID, target, message string, priority chatqueue.Priority) error {
+	if target == "" {
+		return fmt.Errorf("target is required")
+	}
+	return s.sendChat(serverID, "privatemessage "+target, message, priority)
+}
+
+// Say a message on every server known to the webfront. Every server is
+// attempted and the errors are joined
+func (s *Server) Broadcast(message string) error {
+	ids, err := s.ServerIDs()
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("broadcast: no servers: %w", ErrNotFound)
+	}
+
+	var errs []error
+	for _, id := range ids {
+		if err := s.SayOn(id.ID, message); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", id.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Server) sendChat(serverID, command, message string, priority chatqueue.Priority) error {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return fmt.Errorf("message is required")
+	}
+
+	s.mu.Lock()
+	queue := s.queue
+	s.mu.Unlock()
+	prefix := s.commandPrefix()
+
+	lines := SplitMessage(message, s.chatLimit(serverID))
+	commands := make([]string, len(lines))
+	for i, line := range lines {
+		commands[i] = prefix + command + " " + line
+	}
+
+	// The lines of one message are queued together so they stay in order
+	// and are either all sent or none are
+	if queue != nil {
+		return queue.EnqueueAll(serverID, commands, priority)
+	}
+	for _, cmd := range commands {
+		if _, err := s.ExecuteCommandOn(serverID, cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Look up the chat limit for a server's game, remembering the answer
+func (s *Server) chatLimit(serverID string) int {
+	s.mu.Lock()
+	limit, ok := s.chatLimits[serverID]
+	s.mu.Unlock()
+	if ok {
+		return limit
+	}
+
+	status, err := s.Status()
+	if err != nil {
+		return DefaultChatLimit
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.chatLimits == nil {
+		s.chatLimits = make(map[string]int)
+	}
+	for _, st := range status {
+		limit, ok := ChatLimits[st.Game]
+		if !ok {
+			limit = DefaultChatLimit
+		}
+		s.chatLimits[strconv.Itoa(st.ID)] = limit
+	}
+
+	if limit, ok := s.chatLimits[serverID]; ok {
+		return limit
+	}
+	return DefaultChatLimit
+}
+
+// Split a message into lines of at most limit bytes, breaking at spaces
+// where possible. Words longer than a line are cut between characters and
+// never inside a color code. The last color code of a line is carried over
+// to the next one so colors survive the split
+func SplitMessage(message string, limit int) []string {
+	if limit < 3 {
+		limit = DefaultChatLimit
+	}
+
+	var lines []string
+	color := ""
+	for _, paragraph := range strings.Split(message, "\n") {
+		words := strings.Fields(paragraph)
+		line := color
+
+		flush := func() {
+			if strings.TrimSpace(StripColors(line)) != "" {
+				lines = append(lines, line)
+			}
+			color = lastColor(line, color)
+			line = color
+		}
+
+		for _, word := range words {
+			for len(word) > limit-len(color) {
+				if line != color {
+					flush()
+				}
+				cut := cutPoint(word, limit-len(color))
+				line += word[:cut]
+				word = word[cut:]
+				flush()
+			}
+
+			sep := " "
+			if line == color {
+				sep = ""
+				if lastColor(word[:min(2, len(word))], "") != "" {
+					line = ""
+				}
+			}
+			if len(line)+len(sep)+len(word) > limit {
+				flush()
+				sep = ""
+			}
+			line += sep + word
+		}
+		flush()
+	}
+
+	return lines
+}
+
+// Length of the longest prefix of word within n bytes that does not end in
+// the middle of a character or a color code. At least one character or
+// color code is kept so splitting always makes progress
+func cutPoint(word string, n int) int {
+	cut := 0
+	for cut < len(word) {
+		size := 2
+		if !isColorCode(word[cut:]) {
+			_, size = utf8.DecodeRuneInString(word[cut:])
+		}
+		if cut > 0 && cut+size > n {
+			break
+		}
+		cut += size
+	}
+	return cut
+}
+
+func isColorCode(s string) bool {
+	return len(s) >= 2 && s[0] == '^' && s[1] >= '0' && s[1] <= '9'
+}
+
+func lastColor(line, fallback string) string {
+	if i := strings.LastIndex(line, "^"); i >= 0 && i+1 < len(line) {
+		if c := line[i+1]; c >= '0' && c <= '9' {
+			return line[i : i+2]
+		}
+	}
+	return fallback
+}
+
+// Remove game color codes such as ^1 from a string
+func StripColors(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '^' && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9' {
+			i++
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
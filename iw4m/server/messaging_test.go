@@ -0,0 +1,110 @@
+package server
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestSplitMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		limit   int
+		want    []string
+	}{
+		{"short", "hello world", 20, []string{"hello world"}},
+		{"wrap at space", "hello there world", 11, []string{"hello there", "world"}},
+		{"empty", "   ", 20, nil},
+		{"newlines", "one\ntwo", 20, []string{"one", "two"}},
+		{"carry color", "^1red words here", 11, []string{"^1red words", "^1here"}},
+		{"long word", "abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"runes not cut", "ééééé", 5, []string{"éé", "éé", "é"}},
+		{"color code not cut", "abc^1def", 4, []string{"abc", "^1de", "^1f"}},
+		{"invalid limit", "hi", 0, []string{"hi"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitMessage(tt.message, tt.limit)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("SplitMessage(%q, %d) = %q, want %q", tt.message, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitMessageKeepsText(t *testing.T) {
+	messages := []string{
+		"^2Welcome to the server, have fun and follow the rules",
+		"ünïcödé wörds ëvërÿwhërë ïn thïs mëssägë",
+		"日本語のメッセージはとても長いので分割されます",
+		"^1a^2b^3c^4d^5e^6f^7g^8h^9i",
+		"averyveryverylongwordwithoutanyspacesatall^3andacolor",
+	}
+
+	for _, message := range messages {
+		for limit := 3; limit <= 40; limit++ {
+			lines := SplitMessage(message, limit)
+
+			var text []string
+			for i, line := range lines {
+				if !utf8.ValidString(line) {
+					t.Fatalf("SplitMessage(%q, %d) line %d is not valid UTF-8: %q", message, limit, i, line)
+				}
+				// A single character may overflow a limit too small for it
+				if len(line) > limit && utf8.RuneCountInString(StripColors(line)) > 1 {
+					t.Errorf("SplitMessage(%q, %d) line %d is %d bytes: %q", message, limit, i, len(line), line)
+				}
+				text = append(text, StripColors(line))
+			}
+
+			want := strings.ReplaceAll(StripColors(message), " ", "")
+			if got := strings.ReplaceAll(strings.Join(text, ""), " ", ""); got != want {
+				t.Errorf("SplitMessage(%q, %d) lost text: got %q, want %q", message, limit, got, want)
+			}
+		}
+	}
+}
+
+func TestSayQueuedByDefault(t *testing.T) {
+	s, rec := replay(t, "console.json")
+	if s.Queue() == nil {
+		t.Fatal("NewServer did not set up a chat queue")
+	}
+	defer s.Queue().Close()
+
+	if err := s.SayOn("1", "hello"); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for rec.Remaining() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the queued message was never sent")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSayWithoutQueue(t *testing.T) {
+	s, rec := replay(t, "console.json")
+	s.DisableQueue()
+	if s.Queue() != nil {
+		t.Fatal("DisableQueue kept the chat queue")
+	}
+
+	if err := s.SayOn("1", "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if n := rec.Remaining(); n != 0 {
+		t.Errorf("%d recorded exchanges not replayed", n)
+	}
+
+	// Nothing else was recorded, so a synchronous send reports the failure
+	if err := s.SayOn("1", "again"); err == nil {
+		t.Error("SayOn succeeded without a recorded exchange")
+	}
+}
@@ -1,5 +1,7 @@
 package server
 
+import "time"
+
 type ServerStatus struct {
 	ID             int            `json:"id"`
 	IsOnline       bool           `json:"isOnline"`
@@ -11,7 +13,7 @@ type ServerStatus struct {
 	ListenAddress  string         `json:"listenAddress"`
 	ListenPort     int32          `json:"listenPort"`
 	Game           string         `json:"game"`
-	Players        []playerStatus `json:"players"`
+	Players        []PlayerStatus `json:"players"`
 }
 
 type mapStatus struct {
@@ -19,7 +21,7 @@ type mapStatus struct {
 	Alias string `json:"alias"`
 }
 
-type playerStatus struct {
+type PlayerStatus struct {
 	Name           string `json:"name"`
 	Score          int    `json:"score"`
 	Ping           int    `json:"ping"`
@@ -111,6 +113,17 @@ type RecentClient struct {
 	LastSeen  string `json:"last_seen"`
 }
 
+// Penalty is a punishment a client received, as listed on its profile
+type Penalty struct {
+	// Warning, Kick, TempBan, Ban, Flag, Unban or Unflag
+	Type     string `json:"type"`
+	ClientID string `json:"clientId"`
+	Punisher string `json:"punisher"`
+	Reason   string `json:"reason"`
+	// Zero when the webfront did not show when the penalty was issued
+	Time time.Time `json:"time"`
+}
+
 type AuditLog struct {
 	Type       string `json:"type"`
 	Origin     string `json:"origin"`
@@ -123,6 +136,7 @@ type AuditLog struct {
 
 type Admin struct {
 	Name          string `json:"name"`
+	ClientId      string `json:"clientId"`
 	Role          string `json:"role"`
 	Game          string `json:"game"`
 	LastConnected string `json:"last_connected"`
@@ -135,3 +149,25 @@ type TopPlayer struct {
 	Rating string            `json:"rating"`
 	Stats  map[string]string `json:"stats"`
 }
+
+type commandResponse struct {
+	Response string `json:"response"`
+	ClientID int    `json:"clientId"`
+}
+
+type LevelChange struct {
+	ClientID  string `json:"clientId"`
+	Previous  string `json:"previous"`
+	Requested string `json:"requested"`
+	Current   string `json:"current"`
+	Verified  bool   `json:"verified"`
+}
+
+type MapChange struct {
+	ServerID  string `json:"serverId"`
+	Previous  string `json:"previous"`
+	Requested string `json:"requested"`
+	Current   string `json:"current"`
+	GameMode  string `json:"gameMode"`
+	Confirmed bool   `json:"confirmed"`
+}
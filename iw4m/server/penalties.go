@@ -0,0 +1,101 @@
+package server
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Number of penalties read from a client's profile
+const penaltyCount = 100
+
+var (
+	penaltyText = regexp.MustCompile(`(?i)\b(temporarily banned|tempbanned|unbanned|unflagged|warned|kicked|banned|flagged)\b(?:\s+by\s+(.+?))?(?:\s+for\s+(.+?))?\s*$`)
+	agoText     = regexp.MustCompile(`(?i)\b(\d+|an?|one)\s+(second|minute|hour|day|week|month|year)s?\s+ago\b`)
+)
+
+var penaltyTypes = map[string]string{
+	"warned":             "Warning",
+	"kicked":             "Kick",
+	"temporarily banned": "TempBan",
+	"tempbanned":         "TempBan",
+	"banned":             "Ban",
+	"unbanned":           "Unban",
+	"flagged":            "Flag",
+	"unflagged":          "Unflag",
+}
+
+// Read the penalties a client received from the penalty entries of its
+// profile, newest first. The webfront shows relative times such as
+// "3 days ago", so times are approximate
+func (s *Server) Penalties(clientID string) ([]Penalty, error) {
+	endpoint := fmt.Sprintf(
+		"/Client/Meta/%s?offset=0&count=%d&metaType=ReceivedPenalty",
+		url.PathEscape(clientID), penaltyCount,
+	)
+	doc, err := s.getDoc(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var penalties []Penalty
+	doc.Find(".profile-meta-entry").Each(
+		func(i int, entry *goquery.Selection) {
+			text := strings.Join(strings.Fields(entry.Text()), " ")
+			t := parseAgo(text, now)
+			if loc := agoText.FindStringIndex(text); loc != nil {
+				text = strings.TrimSpace(text[:loc[0]] + text[loc[1]:])
+			}
+
+			m := penaltyText.FindStringSubmatch(text)
+			if m == nil {
+				return
+			}
+			penalties = append(penalties, Penalty{
+				Type:     penaltyTypes[strings.ToLower(m[1])],
+				ClientID: clientID,
+				Punisher: strings.TrimSpace(m[2]),
+				Reason:   strings.TrimSpace(m[3]),
+				Time:     t,
+			})
+		})
+
+	return penalties, nil
+}
+
+// Turn a relative time such as "5 minutes ago" into an absolute one, or the
+// zero time when text has none
+func parseAgo(text string, now time.Time) time.Time {
+	m := agoText.FindStringSubmatch(text)
+	if m == nil {
+		return time.Time{}
+	}
+
+	n, err := strconv.Atoi(m[1])
+	if err != nil {
+		n = 1 // "a", "an" or "one"
+	}
+
+	switch strings.ToLower(m[2]) {
+	case "second":
+		return now.Add(-time.Duration(n) * time.Second)
+	case "minute":
+		return now.Add(-time.Duration(n) * time.Minute)
+	case "hour":
+		return now.Add(-time.Duration(n) * time.Hour)
+	case "day":
+		return now.AddDate(0, 0, -n)
+	case "week":
+		return now.AddDate(0, 0, -7*n)
+	case "month":
+		return now.AddDate(0, -n, 0)
+	default:
+		return now.AddDate(-n, 0, 0)
+	}
+}
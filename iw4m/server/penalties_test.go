@@ -0,0 +1,52 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAgo(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		text string
+		want time.Time
+	}{
+		{"Warned by mod for spam 5 minutes ago", now.Add(-5 * time.Minute)},
+		{"an hour ago", now.Add(-time.Hour)},
+		{"3 days ago", now.AddDate(0, 0, -3)},
+		{"2 Weeks Ago", now.AddDate(0, 0, -14)},
+		{"a month ago", now.AddDate(0, -1, 0)},
+		{"no time here", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		if got := parseAgo(tt.text, now); !got.Equal(tt.want) {
+			t.Errorf("parseAgo(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestPenaltyText(t *testing.T) {
+	tests := []struct {
+		text                   string
+		kind, punisher, reason string
+	}{
+		{"Warned by Admin for spamming", "warned", "Admin", "spamming"},
+		{"Temporarily Banned by Mod for camping all game", "temporarily banned", "Mod", "camping all game"},
+		{"Kicked by IW4MAdmin for ping too high", "kicked", "IW4MAdmin", "ping too high"},
+		{"Banned", "banned", "", ""},
+	}
+
+	for _, tt := range tests {
+		m := penaltyText.FindStringSubmatch(tt.text)
+		if m == nil {
+			t.Errorf("penaltyText did not match %q", tt.text)
+			continue
+		}
+		if !strings.EqualFold(m[1], tt.kind) || m[2] != tt.punisher || m[3] != tt.reason {
+			t.Errorf("penaltyText(%q) = %q, %q, %q, want %q, %q, %q", tt.text, m[1], m[2], m[3], tt.kind, tt.punisher, tt.reason)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Warn a client on a server
+func (s *Server) Warn(serverID, target, reason string) error {
+	return s.punish(serverID, "warn "+target, reason)
+}
+
+// Kick a client from a server
+func (s *Server) Kick(serverID, target, reason string) error {
+	return s.punish(serverID, "kick "+target, reason)
+}
+
+// Ban a client for a limited time. The duration is rounded down to whole
+// minutes and must be at least one minute
+func (s *Server) TempBan(serverID, target string, duration time.Duration, reason string) error {
+	if duration < time.Minute {
+		return fmt.Errorf("tempban duration must be at least a minute")
+	}
+	return s.punish(serverID, "tempban "+target+" "+FormatBanDuration(duration), reason)
+}
+
+// Ban a client permanently
+func (s *Server) Ban(serverID, target, reason string) error {
+	return s.punish(serverID, "ban "+target, reason)
+}
+
+func (s *Server) punish(serverID, command, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return fmt.Errorf("reason is required")
+	}
+
+	_, err := s.ExecuteCommandOn(serverID, s.commandPrefix()+command+" "+reason)
+	return err
+}
+
+// Format a duration the way IW4M-Admin parses tempban lengths, using the
+// largest unit that represents it exactly, e.g. "90m", "2h" or "1w"
+func FormatBanDuration(d time.Duration) string {
+	minutes := int64(d / time.Minute)
+	units := []struct {
+		suffix  string
+		minutes int64
+	}{
+		{"y", 365 * 24 * 60},
+		{"w", 7 * 24 * 60},
+		{"d", 24 * 60},
+		{"h", 60},
+	}
+
+	for _, u := range units {
+		if minutes >= u.minutes && minutes%u.minutes == 0 {
+			return fmt.Sprintf("%d%s", minutes/u.minutes, u.suffix)
+		}
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// How long map commands wait for Status() to reflect the change
+	MapConfirmTimeout = 45 * time.Second
+	// How often Status() is polled while waiting
+	MapConfirmInterval = 3 * time.Second
+)
+
+// Read the status of a single server
+func (s *Server) ServerStatus(serverID string) (*ServerStatus, error) {
+	status, err := s.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range status {
+		if strconv.Itoa(status[i].ID) == serverID {
+			return &status[i], nil
+		}
+	}
+	return nil, fmt.Errorf("server %s: %w", serverID, ErrNotFound)
+}
+
+// Change the map on a server, accepting display names such as "Rust" as
+// well as engine codes, and wait until Status() reports the new map
+func (s *Server) ChangeMap(serverID, name string) (*MapChange, error) {
+	before, err := s.ServerStatus(serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	code, ok := ResolveMap(before.Game, name)
+	if !ok {
+		return nil, fmt.Errorf("map %q for %s: %w", name, before.Game, ErrNotFound)
+	}
+
+	if _, err := s.ExecuteCommandOn(serverID, s.commandPrefix()+"map "+code); err != nil {
+		return nil, err
+	}
+
+	return s.confirmMap(serverID, before, code, func(current *ServerStatus) bool {
+		return current != nil && strings.EqualFold(current.Map.Name, code)
+	})
+}
+
+// Advance the server to the next map in its rotation
+func (s *Server) RotateMap(serverID string) (*MapChange, error) {
+	before, err := s.ServerStatus(serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.ExecuteCommandOn(serverID, s.commandPrefix()+"maprotate"); err != nil {
+		return nil, err
+	}
+
+	return s.confirmMap(serverID, before, "", func(current *ServerStatus) bool {
+		return current != nil && current.Map.Name != before.Map.Name
+	})
+}
+
+// Restart the current map. The restart is confirmed once Status() shows
+// the server dropping offline and coming back on the same map, or the
+// scores of the players still connected resetting. Otherwise it is
+// reported as unconfirmed, as the map name alone cannot tell a restart
+// from nothing happening
+func (s *Server) RestartMap(serverID string) (*MapChange, error) {
+	return s.restart(serverID, "map_restart")
+}
+
+// Restart the current round without reloading the map. Confirmed the same
+// way as RestartMap, which usually means by the scores resetting
+func (s *Server) FastRestart(serverID string) (*MapChange, error) {
+	return s.restart(serverID, "fast_restart")
+}
+
+func (s *Server) restart(serverID, dvar string) (*MapChange, error) {
+	before, err := s.ServerStatus(serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.ExecuteCommandOn(serverID, s.commandPrefix()+"rcon "+dvar); err != nil {
+		return nil, err
+	}
+
+	wentDown := false
+	return s.confirmMap(serverID, before, before.Map.Name, func(current *ServerStatus) bool {
+		if current == nil || !current.IsOnline {
+			wentDown = true
+			return false
+		}
+		return current.Map.Name == before.Map.Name && (wentDown || scoresReset(before, current))
+	})
+}
+
+// Report whether every player still connected who had scored now has a
+// lower score, which happens when the round restarts
+func scoresReset(before, current *ServerStatus) bool {
+	scores := make(map[string]int, len(before.Players))
+	for _, p := range before.Players {
+		if p.Score > 0 {
+			scores[p.Name] = p.Score
+		}
+	}
+
+	matched := 0
+	for _, p := range current.Players {
+		score, ok := scores[p.Name]
+		if !ok {
+			continue
+		}
+		if p.Score >= score {
+			return false
+		}
+		matched++
+	}
+	return matched > 0
+}
+
+// Poll Status() until done reports the change took effect or the timeout
+// passes. done is called with nil while the server cannot be read. An
+// unconfirmed change is not an error, the command was accepted
+func (s *Server) confirmMap(serverID string, before *ServerStatus, requested string, done func(*ServerStatus) bool) (*MapChange, error) {
+	change := &MapChange{
+		ServerID:  serverID,
+		Previous:  before.Map.Name,
+		Requested: requested,
+		Current:   before.Map.Name,
+		GameMode:  before.GameMode,
+	}
+
+	deadline := time.Now().Add(MapConfirmTimeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(MapConfirmInterval)
+
+		current, err := s.ServerStatus(serverID)
+		if err != nil {
+			done(nil) // the server is often unreachable while loading
+			continue
+		}
+
+		change.Current = current.Map.Name
+		change.GameMode = current.GameMode
+		if done(current) {
+			change.Confirmed = true
+			return change, nil
+		}
+	}
+
+	s.iw4m.Log().Warn("map change not confirmed",
+		"server", serverID, "requested", requested, "current", change.Current)
+	return change, nil
+}
@@ -0,0 +1,35 @@
+package server
+
+import "testing"
+
+func TestScoresReset(t *testing.T) {
+	status := func(scores map[string]int) *ServerStatus {
+		st := &ServerStatus{IsOnline: true}
+		for name, score := range scores {
+			st.Players = append(st.Players, PlayerStatus{Name: name, Score: score})
+		}
+		return st
+	}
+
+	tests := []struct {
+		name            string
+		before, current map[string]int
+		want            bool
+	}{
+		{"unchanged", map[string]int{"a": 100, "b": 50}, map[string]int{"a": 100, "b": 50}, false},
+		{"still scoring", map[string]int{"a": 100}, map[string]int{"a": 150}, false},
+		{"reset", map[string]int{"a": 100, "b": 50}, map[string]int{"a": 0, "b": 10}, true},
+		{"one player not reset", map[string]int{"a": 100, "b": 50}, map[string]int{"a": 0, "b": 60}, false},
+		{"nobody had scored", map[string]int{"a": 0}, map[string]int{"a": 0}, false},
+		{"everyone left", map[string]int{"a": 100}, map[string]int{"c": 0}, false},
+		{"joiner ignored", map[string]int{"a": 100}, map[string]int{"a": 0, "c": 500}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scoresReset(status(tt.before), status(tt.current)); got != tt.want {
+				t.Errorf("scoresReset = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,18 +5,28 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/Yallamaztar/iw4m-go/iw4m"
+	"github.com/Yallamaztar/iw4m-go/iw4m/chatqueue"
 )
 
 type Server struct {
 	iw4m *iw4m.IW4MWrapper
+
+	mu         sync.Mutex
+	chatLimits map[string]int
+	queue      *chatqueue.Queue
+	prefix     string
 }
 
-// Create a new Server wrapper
+// Create a new Server wrapper. Chat goes through a rate limited queue with
+// the default chatqueue.Options, see UseQueue and DisableQueue
 func NewServer(iw4m *iw4m.IW4MWrapper) *Server {
-	return &Server{iw4m: iw4m}
+	s := &Server{iw4m: iw4m}
+	s.queue = s.newQueue(chatqueue.Options{})
+	return s
 }
 
 func (s *Server) Status() ([]ServerStatus, error) {
@@ -65,6 +75,7 @@ func (s *Server) MapName() (string, error) {
 
 	div := doc.Find("div.col-12.align-self-center.text-center.text-lg-left.col-lg-4").First()
 	if div.Length() == 0 {
+		s.warnMissing("/", "div.col-12.align-self-center.text-center.text-lg-left.col-lg-4")
 		return "", fmt.Errorf("map name not found")
 	}
 
@@ -79,6 +90,7 @@ func (s *Server) GameMode() (string, error) {
 
 	div := doc.Find("div.col-12.align-self-center.text-center.text-lg-left.col-lg-4").First()
 	if div.Length() == 0 {
+		s.warnMissing("/", "div.col-12.align-self-center.text-center.text-lg-left.col-lg-4")
 		return "", fmt.Errorf("map name not found")
 	}
 
@@ -103,6 +115,7 @@ func (s *Server) IW4MVersion() (string, error) {
 		})
 
 	if version == "" {
+		s.warnMissing("/", "a.sidebar-link span.text-primary")
 		return "", fmt.Errorf("iw4m-admin version not found")
 	}
 
@@ -117,6 +130,7 @@ func (s *Server) LoggedInAs() (string, error) {
 
 	div := doc.Find("div.sidebar-link.font-size-12.font-weight-light")
 	if div.Length() == 0 {
+		s.warnMissing("/", "div.sidebar-link.font-size-12.font-weight-light")
 		return "", fmt.Errorf("username not found")
 	}
 
@@ -235,6 +249,10 @@ func (s *Server) Help() (*Help, error) {
 			help.Sections[title] = commands
 		})
 
+	if len(help.Sections) == 0 {
+		s.warnMissing("/Home/Help", "div.command-assembly-container")
+	}
+
 	return help, nil
 }
 
@@ -259,6 +277,10 @@ func (s *Server) ServerIDs() ([]ServerID, error) {
 			})
 		})
 
+	if len(serverIDs) == 0 {
+		s.warnMissing("/Console", "select#console_server_select option")
+	}
+
 	return serverIDs, nil
 }
 
@@ -350,11 +372,12 @@ func (s *Server) ListPlayers() ([]Players, error) {
 			func(i int, sel *goquery.Selection) {
 				colorcode := sel.Find("colorcode")
 				if colorcode.Length() > 0 {
+					href := strings.TrimSpace(sel.AttrOr("href", ""))
 					players = append(players, Players{
 						Role:     role,
 						Name:     strings.TrimSpace(colorcode.Text()),
-						ClientId: strings.TrimSpace(sel.AttrOr("href", ""))[16:],
-						URL:      strings.TrimSpace(sel.AttrOr("href", "")),
+						ClientId: clientIDFromHref(href),
+						URL:      href,
 					})
 				}
 			})
@@ -371,7 +394,9 @@ func (s *Server) StockRoles() ([]string, error) {
 
 	var roles []string
 	selectTag := doc.Find("select[name='level']")
-	if selectTag.Length() > 0 {
+	if selectTag.Length() == 0 {
+		s.warnMissing("/Action/editForm/?id=2&meta=", "select[name='level']")
+	} else {
 		selectTag.Find("option").Each(
 			func(i int, sel *goquery.Selection) {
 				role, exists := sel.Attr("value")
@@ -452,6 +477,7 @@ func (s *Server) RecentAuditLog() (*AuditLog, error) {
 
 	tbody := doc.Find("#audit_log_table_body")
 	if tbody.Length() == 0 {
+		s.warnMissing("/Admin/AuditLog", "#audit_log_table_body")
 		return nil, nil // nothing found
 	}
 
@@ -499,6 +525,7 @@ func (s *Server) AuditLogs(count int) ([]AuditLog, error) {
 
 	tbody := doc.Find("#audit_log_table_body")
 	if tbody.Length() == 0 {
+		s.warnMissing("/Admin/AuditLog", "#audit_log_table_body")
 		return []AuditLog{}, nil
 	}
 
@@ -572,7 +599,8 @@ func (s *Server) Admins(role string, count int) ([]Admin, error) {
 						return
 					}
 
-					name := strings.TrimSpace(row.Find("a.text-force-break").Text())
+					link := row.Find("a.text-force-break")
+					name := strings.TrimSpace(link.Text())
 					game := "N/A"
 					if badge := row.Find("div.badge"); badge.Length() > 0 {
 						game = strings.TrimSpace(badge.Text())
@@ -582,6 +610,7 @@ func (s *Server) Admins(role string, count int) ([]Admin, error) {
 
 					admins = append(admins, Admin{
 						Name:          name,
+						ClientId:      clientIDFromHref(strings.TrimSpace(link.AttrOr("href", ""))),
 						Role:          tableRole,
 						Game:          game,
 						LastConnected: lastConnected,
@@ -642,27 +671,59 @@ func (s *Server) TopPlayers(count int) ([]TopPlayer, error) {
 	return players, nil
 }
 
-func (s *Server) PlayerCount() int {
+// Count the players currently online
+func (s *Server) CountPlayers() (int, error) {
 	players, err := s.ListPlayers()
 	if err != nil {
-		return 0
+		return 0, err
+	}
+	return len(players), nil
+}
+
+// Deprecated: use CountPlayers, which reports errors instead of returning 0
+func (s *Server) PlayerCount() int {
+	count, _ := s.CountPlayers()
+	return count
+}
+
+// Report whether every client slot is taken. A webfront without any
+// client slots is never full
+func (s *Server) ServerFull() (bool, error) {
+	info, err := s.Info()
+	if err != nil {
+		return false, err
 	}
-	return len(players)
+	if info.TotalClientSlots == 0 {
+		return false, nil
+	}
+	return info.TotalConnectedClients >= info.TotalClientSlots, nil
 }
 
+// Deprecated: use ServerFull, which reports errors instead of returning false
 func (s *Server) IsServerFull() bool {
-	info, _ := s.Info()
-	return info.TotalConnectedClients >= info.TotalClientSlots
+	full, _ := s.ServerFull()
+	return full
 }
 
-func (s *Server) FindAdmin(username string) Admin {
-	admins, _ := s.Admins("all", 1000)
+// Look up a privileged client by name, returning ErrNotFound if there is none
+func (s *Server) LookupAdmin(username string) (Admin, error) {
+	admins, err := s.Admins("all", 1000)
+	if err != nil {
+		return Admin{}, err
+	}
+
 	for _, admin := range admins {
-		if strings.EqualFold(strings.ToLower(admin.Name), strings.ToLower(username)) {
-			return admin
+		if strings.EqualFold(admin.Name, username) {
+			return admin, nil
 		}
 	}
-	return Admin{}
+	return Admin{}, fmt.Errorf("admin %q: %w", username, ErrNotFound)
+}
+
+// Deprecated: use LookupAdmin, which reports errors instead of returning Admin{}
+func (s *Server) FindAdmin(username string) Admin {
+	admin, _ := s.LookupAdmin(username)
+	return admin
 }
 
 func (s *Server) OnlinePlayersByRole(role string) ([]Players, error) {
@@ -682,7 +743,21 @@ func (s *Server) OnlinePlayersByRole(role string) ([]Players, error) {
 	return found, nil
 }
 
+// Detect the command prefix from the most recent audit log entry, returning
+// ErrNotFound when the audit log is empty
+func (s *Server) DetectCommandPrefix() (string, error) {
+	log, err := s.RecentAuditLog()
+	if err != nil {
+		return "", err
+	}
+	if log == nil || log.Data == "" {
+		return "", fmt.Errorf("command prefix: %w", ErrNotFound)
+	}
+	return log.Data[:1], nil
+}
+
+// Deprecated: use DetectCommandPrefix, which reports errors instead of returning ""
 func (s *Server) CommandPrefix() string {
-	log, _ := s.RecentAuditLog()
-	return string(log.Data[0])
+	prefix, _ := s.DetectCommandPrefix()
+	return prefix
 }
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 )
@@ -18,23 +21,52 @@ func readBody(res *http.Response) ([]byte, error) {
 	return body, nil
 }
 
-func getDocFromRes(res *http.Response) (*goquery.Document, error) {
+func (s *Server) getDoc(endpoint string) (*goquery.Document, error) {
+	res, err := s.iw4m.DoRequest(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
 	body, err := readBody(res)
 	if err != nil {
 		return nil, err
 	}
 
+	start := time.Now()
 	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	s.iw4m.ObserveParse(endpoint, time.Since(start))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
 	return doc, nil
 }
 
-func (s *Server) getDoc(endpoint string) (*goquery.Document, error) {
-	res, err := s.iw4m.DoRequest(endpoint)
-	if err != nil {
-		return nil, err
+// Extract the client id from a profile link such as /Client/Profile/123
+func clientIDFromHref(href string) string {
+	href = strings.TrimRight(href, "/")
+	if i := strings.LastIndex(href, "/"); i >= 0 {
+		return href[i+1:]
+	}
+	return href
+}
+
+// Log that a selector the scraper depends on matched nothing, which usually
+// means the webfront markup changed or the session is not logged in
+func (s *Server) warnMissing(endpoint, selector string) {
+	s.iw4m.Log().Warn("selector matched nothing", "endpoint", endpoint, "selector", selector)
+}
+
+// Find the server a player is connected to by name, comparing names with
+// and without color codes. The first match wins when several servers have
+// a player of that name
+func LocatePlayer(status []ServerStatus, name string) (string, PlayerStatus, bool) {
+	stripped := StripColors(name)
+	for _, s := range status {
+		for _, p := range s.Players {
+			if p.Name == name || StripColors(p.Name) == stripped {
+				return strconv.Itoa(s.ID), p, true
+			}
+		}
 	}
-	return getDocFromRes(res)
+	return "", PlayerStatus{}, false
 }
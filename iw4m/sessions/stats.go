@@ -0,0 +1,222 @@
+package sessions
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/history"
+)
+
+type Period int
+
+const (
+	Day Period = iota
+	// Weeks start on Monday
+	Week
+)
+
+type Bucket struct {
+	Start    time.Time     `json:"start"`
+	Playtime time.Duration `json:"playtime"`
+	// Sessions overlapping the bucket
+	Sessions int `json:"sessions"`
+	// Distinct players with playtime in the bucket
+	Players int `json:"players"`
+}
+
+type Player struct {
+	Name      string        `json:"name"`
+	Sessions  int           `json:"sessions"`
+	Playtime  time.Duration `json:"playtime"`
+	FirstSeen time.Time     `json:"firstSeen"`
+	LastSeen  time.Time     `json:"lastSeen"`
+	// Distinct days played since Classification.Since
+	Days     int  `json:"days"`
+	Regular  bool `json:"regular"`
+	Newcomer bool `json:"newcomer"`
+}
+
+type Classification struct {
+	// Players first seen at or after Since are newcomers. Pass sessions
+	// from before Since too, or every player looks new
+	Since time.Time
+	// Days played since Since to count as a regular, default 3
+	RegularDays int
+	// Location days are counted in, default UTC
+	Location *time.Location
+}
+
+// Total playtime per day or week in loc, nil meaning UTC. Sessions crossing
+// a boundary are split between buckets. Buckets are in time order and
+// periods without play are left out
+func Playtime(sessions []history.Session, period Period, loc *time.Location) []Bucket {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	type acc struct {
+		bucket  Bucket
+		players map[string]bool
+	}
+	buckets := make(map[time.Time]*acc)
+
+	for _, s := range sessions {
+		last := bucketStart(s.End, period, loc)
+		for start := bucketStart(s.Start, period, loc); !start.After(last); start = nextBucket(start, period) {
+			from := later(start, s.Start)
+			to := earlier(nextBucket(start, period), s.End)
+			// A session ending on a boundary has nothing in the next
+			// bucket, while a zero length session still counts once
+			if !to.After(from) && start.After(bucketStart(s.Start, period, loc)) {
+				continue
+			}
+
+			a := buckets[start]
+			if a == nil {
+				a = &acc{bucket: Bucket{Start: start}, players: make(map[string]bool)}
+				buckets[start] = a
+			}
+			a.bucket.Playtime += to.Sub(from)
+			a.bucket.Sessions++
+			a.players[key(s.Player)] = true
+		}
+	}
+
+	result := make([]Bucket, 0, len(buckets))
+	for _, a := range buckets {
+		a.bucket.Players = len(a.players)
+		result = append(result, a.bucket)
+	}
+	slices.SortFunc(result, func(a, b Bucket) int { return a.Start.Compare(b.Start) })
+	return result
+}
+
+// Most sessions open at once and when that was first reached. Filter the
+// sessions by server first for a per-server peak
+func Peak(sessions []history.Session) (int, time.Time) {
+	type edge struct {
+		at    time.Time
+		delta int
+	}
+	edges := make([]edge, 0, 2*len(sessions))
+	for _, s := range sessions {
+		edges = append(edges, edge{s.Start, 1}, edge{s.End, -1})
+	}
+	// Starts sort before ends at the same instant, so back to back
+	// sightings count as overlapping
+	slices.SortFunc(edges, func(a, b edge) int {
+		if c := a.at.Compare(b.at); c != 0 {
+			return c
+		}
+		return b.delta - a.delta
+	})
+
+	peak, current := 0, 0
+	var at time.Time
+	for _, e := range edges {
+		current += e.delta
+		if current > peak {
+			peak, at = current, e.at
+		}
+	}
+	return peak, at
+}
+
+// Mean session length, 0 without sessions
+func AverageLength(sessions []history.Session) time.Duration {
+	if len(sessions) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, s := range sessions {
+		total += s.Duration()
+	}
+	return total / time.Duration(len(sessions))
+}
+
+// Summarise sessions per player and tell regulars from newcomers. Players
+// are sorted by playtime, most first
+func Players(sessions []history.Session, c Classification) []Player {
+	if c.RegularDays <= 0 {
+		c.RegularDays = 3
+	}
+	if c.Location == nil {
+		c.Location = time.UTC
+	}
+
+	players := make(map[string]*Player)
+	days := make(map[string]map[time.Time]bool)
+
+	for _, s := range sessions {
+		k := key(s.Player)
+		p := players[k]
+		if p == nil {
+			p = &Player{Name: s.Player, FirstSeen: s.Start, LastSeen: s.End}
+			players[k] = p
+			days[k] = make(map[time.Time]bool)
+		}
+
+		p.Sessions++
+		p.Playtime += s.Duration()
+		p.FirstSeen = earlier(p.FirstSeen, s.Start)
+		if s.End.After(p.LastSeen) {
+			p.Name = s.Player // most recent spelling
+			p.LastSeen = s.End
+		}
+
+		if !s.End.Before(c.Since) {
+			// Like Playtime, a day the session only touches at midnight
+			// does not count
+			first := bucketStart(later(s.Start, c.Since), Day, c.Location)
+			for d := first; d.Equal(first) || d.Before(s.End); d = nextBucket(d, Day) {
+				days[k][d] = true
+			}
+		}
+	}
+
+	result := make([]Player, 0, len(players))
+	for k, p := range players {
+		p.Days = len(days[k])
+		p.Regular = p.Days >= c.RegularDays
+		p.Newcomer = !p.FirstSeen.Before(c.Since)
+		result = append(result, *p)
+	}
+	slices.SortFunc(result, func(a, b Player) int {
+		if a.Playtime != b.Playtime {
+			return cmp.Compare(b.Playtime, a.Playtime)
+		}
+		return a.FirstSeen.Compare(b.FirstSeen)
+	})
+	return result
+}
+
+func bucketStart(t time.Time, period Period, loc *time.Location) time.Time {
+	t = t.In(loc)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	if period == Week {
+		day = day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	}
+	return day
+}
+
+func nextBucket(start time.Time, period Period) time.Time {
+	if period == Week {
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+func earlier(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
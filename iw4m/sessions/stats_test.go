@@ -0,0 +1,167 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/history"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+var day = time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC) // a Monday
+
+func session(player string, start, end time.Duration) history.Session {
+	return history.Session{ServerID: "1", Player: player, Start: day.Add(start), End: day.Add(end)}
+}
+
+func TestPlaytime(t *testing.T) {
+	h := time.Hour
+
+	tests := []struct {
+		name     string
+		sessions []history.Session
+		period   Period
+		want     []Bucket
+	}{
+		{
+			"within a day",
+			[]history.Session{session("a", 10*h, 12*h), session("b", 11*h, 12*h)},
+			Day,
+			[]Bucket{{Start: day, Playtime: 3 * h, Sessions: 2, Players: 2}},
+		},
+		{
+			"across midnight",
+			[]history.Session{session("a", 23*h, 25*h)},
+			Day,
+			[]Bucket{
+				{Start: day, Playtime: h, Sessions: 1, Players: 1},
+				{Start: day.AddDate(0, 0, 1), Playtime: h, Sessions: 1, Players: 1},
+			},
+		},
+		{
+			"ending on midnight",
+			[]history.Session{session("a", 22*h, 24*h)},
+			Day,
+			[]Bucket{{Start: day, Playtime: 2 * h, Sessions: 1, Players: 1}},
+		},
+		{
+			"zero length",
+			[]history.Session{session("a", 24*h, 24*h)},
+			Day,
+			[]Bucket{{Start: day.AddDate(0, 0, 1), Sessions: 1, Players: 1}},
+		},
+		{
+			"weeks start on monday",
+			[]history.Session{session("a", -h, h), session("a", 2*h, 3*h)},
+			Week,
+			[]Bucket{
+				{Start: day.AddDate(0, 0, -7), Playtime: h, Sessions: 1, Players: 1},
+				{Start: day, Playtime: 2 * h, Sessions: 2, Players: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Playtime(tt.sessions, tt.period, nil)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Playtime = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Start.Equal(tt.want[i].Start) || got[i].Playtime != tt.want[i].Playtime ||
+					got[i].Sessions != tt.want[i].Sessions || got[i].Players != tt.want[i].Players {
+					t.Errorf("bucket %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPeak(t *testing.T) {
+	h := time.Hour
+	sessions := []history.Session{
+		session("a", 0, 3*h),
+		session("b", h, 2*h),
+		session("c", 2*h, 4*h),
+		session("d", 5*h, 6*h),
+	}
+
+	count, at := Peak(sessions)
+	if count != 3 || !at.Equal(day.Add(2*h)) {
+		t.Errorf("Peak = %d at %v, want 3 at %v", count, at, day.Add(2*h))
+	}
+	if count, _ := Peak(nil); count != 0 {
+		t.Errorf("Peak(nil) = %d, want 0", count)
+	}
+}
+
+func TestAverageLength(t *testing.T) {
+	sessions := []history.Session{session("a", 0, time.Hour), session("b", 0, 3*time.Hour)}
+	if got := AverageLength(sessions); got != 2*time.Hour {
+		t.Errorf("AverageLength = %v, want 2h", got)
+	}
+	if got := AverageLength(nil); got != 0 {
+		t.Errorf("AverageLength(nil) = %v, want 0", got)
+	}
+}
+
+func TestPlayers(t *testing.T) {
+	h := time.Hour
+	sessions := []history.Session{
+		// Regular since before the window, three days in it
+		session("reg", -240*h, -239*h),
+		session("reg", 10*h, 11*h),
+		session("reg", 34*h, 35*h),
+		session("reg", 58*h, 59*h),
+		// New in the window, its session ends on midnight
+		session("^2New", 22*h, 24*h),
+	}
+
+	players := Players(sessions, Classification{Since: day, RegularDays: 3})
+	if len(players) != 2 {
+		t.Fatalf("Players = %+v, want 2 players", players)
+	}
+
+	reg, fresh := players[0], players[1]
+	if reg.Name != "reg" || reg.Sessions != 4 || reg.Playtime != 4*h || reg.Days != 3 || !reg.Regular || reg.Newcomer {
+		t.Errorf("regular = %+v", reg)
+	}
+	if fresh.Name != "^2New" || fresh.Days != 1 || fresh.Regular || !fresh.Newcomer {
+		t.Errorf("newcomer = %+v", fresh)
+	}
+}
+
+func TestRebuild(t *testing.T) {
+	status := func(names ...string) server.ServerStatus {
+		st := server.ServerStatus{ID: 1, IsOnline: true}
+		for _, name := range names {
+			st.Players = append(st.Players, server.PlayerStatus{Name: name})
+		}
+		return st
+	}
+	snap := func(minutes int, st server.ServerStatus) history.Snapshot {
+		return history.Snapshot{Time: day.Add(time.Duration(minutes) * time.Minute), ServerID: "1", Status: st}
+	}
+
+	snapshots := []history.Snapshot{
+		snap(0, status("a", "b")),
+		snap(1, status("a", "^3B")),
+		snap(2, status("a")),
+		// The tracker was down, so a's session is split
+		snap(20, status("a")),
+		snap(21, status("a")),
+	}
+
+	sessions := Rebuild(snapshots, 5*time.Minute)
+
+	got := make(map[string][]time.Duration)
+	for _, s := range sessions {
+		got[key(s.Player)] = append(got[key(s.Player)], s.Duration())
+	}
+	if len(got["b"]) != 1 || got["b"][0] != time.Minute {
+		t.Errorf("sessions of b = %v, want [1m]", got["b"])
+	}
+	if len(got["a"]) != 2 || got["a"][0]+got["a"][1] != 3*time.Minute {
+		t.Errorf("sessions of a = %v, want 2m and 1m", got["a"])
+	}
+}
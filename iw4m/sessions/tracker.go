@@ -0,0 +1,202 @@
+package sessions
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/history"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+// Source is the part of the server API the tracker polls
+type Source interface {
+	Status() ([]server.ServerStatus, error)
+}
+
+// Tracker derives player sessions from successive status snapshots. A
+// session starts at the first snapshot listing a player and ends at the
+// last one, so its accuracy is the polling interval. Players are told
+// apart by name, as that is all the status page shows
+type Tracker struct {
+	store *history.Store
+
+	// Sessions still open when no snapshot of their server arrived for
+	// MaxGap are closed at the last sighting, so tracker downtime is not
+	// counted as playtime. Default 5m
+	MaxGap time.Duration
+	// Called when polling or writing fails, nil ignores errors
+	OnError func(error)
+
+	mu   sync.Mutex
+	open map[string]map[string]*history.Session
+	last map[string]time.Time
+}
+
+// Create a tracker storing closed sessions in store. A nil store keeps
+// sessions in memory only, as Observe still returns them
+func NewTracker(store *history.Store) *Tracker {
+	return &Tracker{
+		store:  store,
+		MaxGap: 5 * time.Minute,
+		open:   make(map[string]map[string]*history.Session),
+		last:   make(map[string]time.Time),
+	}
+}
+
+// Poll every interval until ctx is cancelled, then close open sessions
+func (t *Tracker) Run(ctx context.Context, source Source, interval time.Duration) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if status, err := source.Status(); err != nil {
+			t.fail(err)
+		} else if _, err := t.Observe(time.Now().UTC(), status); err != nil {
+			t.fail(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			_, err := t.Flush()
+			t.fail(err)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Apply a Status() result taken at now and return the sessions it closed.
+// Sessions on servers missing from status stay open until MaxGap has passed
+// since the server was last seen, then close at their last sighting
+func (t *Tracker) Observe(now time.Time, status []server.ServerStatus) ([]history.Session, error) {
+	t.mu.Lock()
+	var closed []history.Session
+	present := make(map[string]bool, len(status))
+	for _, st := range status {
+		serverID := strconv.Itoa(st.ID)
+		present[serverID] = true
+		closed = append(closed, t.observe(now, serverID, st.Players)...)
+	}
+	for serverID, last := range t.last {
+		if !present[serverID] && now.Sub(last) > t.MaxGap {
+			closed = append(closed, t.closeAll(serverID)...)
+			delete(t.last, serverID)
+		}
+	}
+	t.mu.Unlock()
+
+	return closed, t.save(closed)
+}
+
+// Close every open session at its last sighting and return them
+func (t *Tracker) Flush() ([]history.Session, error) {
+	t.mu.Lock()
+	var closed []history.Session
+	for serverID := range t.open {
+		closed = append(closed, t.closeAll(serverID)...)
+	}
+	t.mu.Unlock()
+
+	return closed, t.save(closed)
+}
+
+// Sessions in progress, ending at the last sighting of their player
+func (t *Tracker) Open() []history.Session {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var sessions []history.Session
+	for _, players := range t.open {
+		for _, s := range players {
+			sessions = append(sessions, *s)
+		}
+	}
+	return sessions
+}
+
+// Derive sessions from recorded snapshots, as returned by
+// history.Store.Snapshots. Nothing is written
+func Rebuild(snapshots []history.Snapshot, maxGap time.Duration) []history.Session {
+	t := NewTracker(nil)
+	if maxGap > 0 {
+		t.MaxGap = maxGap
+	}
+
+	var sessions []history.Session
+	for _, snap := range snapshots {
+		sessions = append(sessions, t.observe(snap.Time, snap.ServerID, snap.Status.Players)...)
+	}
+	rest, _ := t.Flush()
+	return append(sessions, rest...)
+}
+
+func (t *Tracker) observe(now time.Time, serverID string, players []server.PlayerStatus) []history.Session {
+	var closed []history.Session
+	if last, ok := t.last[serverID]; ok && now.Sub(last) > t.MaxGap {
+		closed = t.closeAll(serverID)
+	}
+	t.last[serverID] = now
+
+	open := t.open[serverID]
+	if open == nil {
+		open = make(map[string]*history.Session)
+		t.open[serverID] = open
+	}
+
+	seen := make(map[string]bool, len(players))
+	for _, p := range players {
+		k := key(p.Name)
+		if k == "" || seen[k] {
+			continue
+		}
+		seen[k] = true
+
+		if s, ok := open[k]; ok {
+			s.End = now
+			continue
+		}
+		open[k] = &history.Session{ServerID: serverID, Player: p.Name, Start: now, End: now}
+	}
+
+	for k, s := range open {
+		if !seen[k] {
+			closed = append(closed, *s)
+			delete(open, k)
+		}
+	}
+	return closed
+}
+
+func (t *Tracker) closeAll(serverID string) []history.Session {
+	var closed []history.Session
+	for _, s := range t.open[serverID] {
+		closed = append(closed, *s)
+	}
+	delete(t.open, serverID)
+	return closed
+}
+
+func (t *Tracker) save(sessions []history.Session) error {
+	if t.store == nil || len(sessions) == 0 {
+		return nil
+	}
+	return t.store.RecordSessions(sessions...)
+}
+
+func (t *Tracker) fail(err error) {
+	if err != nil && t.OnError != nil {
+		t.OnError(err)
+	}
+}
+
+// Player identity used to match sessions across snapshots
+func key(name string) string {
+	return strings.ToLower(strings.TrimSpace(server.StripColors(name)))
+}
@@ -0,0 +1,64 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/history"
+	"github.com/Yallamaztar/iw4m-go/iw4m/server"
+)
+
+func TestObserveAbsentServer(t *testing.T) {
+	status := func(id int, names ...string) server.ServerStatus {
+		st := server.ServerStatus{ID: id, IsOnline: true}
+		for _, name := range names {
+			st.Players = append(st.Players, server.PlayerStatus{Name: name})
+		}
+		return st
+	}
+	at := func(minutes int) time.Time { return day.Add(time.Duration(minutes) * time.Minute) }
+
+	tracker := NewTracker(nil)
+	steps := []struct {
+		minutes int
+		status  []server.ServerStatus
+		closed  []history.Session
+		open    int
+	}{
+		{0, []server.ServerStatus{status(1, "a"), status(2, "b")}, nil, 2},
+		{1, []server.ServerStatus{status(1, "a"), status(2, "b")}, nil, 2},
+		// Server 2 drops out of the status, b stays open within MaxGap
+		{4, []server.ServerStatus{status(1, "a")}, nil, 2},
+		{6, []server.ServerStatus{status(1, "a")}, nil, 2},
+		// MaxGap passed since server 2 was seen, b ends at its last sighting
+		{7, []server.ServerStatus{status(1, "a")}, []history.Session{session("b", time.Duration(0), time.Minute)}, 1},
+		{8, []server.ServerStatus{status(1, "a")}, nil, 1},
+		// Server 2 is back and b starts a new session
+		{9, []server.ServerStatus{status(1, "a"), status(2, "b")}, nil, 2},
+	}
+
+	for _, step := range steps {
+		closed, err := tracker.Observe(at(step.minutes), step.status)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range step.closed {
+			step.closed[i].ServerID = "2"
+		}
+		if len(closed) != len(step.closed) || len(closed) > 0 && closed[0] != step.closed[0] {
+			t.Errorf("minute %d: closed %+v, want %+v", step.minutes, closed, step.closed)
+		}
+		if open := tracker.Open(); len(open) != step.open {
+			t.Errorf("minute %d: %d sessions open, want %d", step.minutes, len(open), step.open)
+		}
+	}
+
+	for _, s := range tracker.Open() {
+		if s.Player == "b" && !s.Start.Equal(at(9)) {
+			t.Errorf("b's new session starts at %v, want %v", s.Start, at(9))
+		}
+		if s.Player == "a" && !s.Start.Equal(at(0)) {
+			t.Errorf("a's session starts at %v, want %v", s.Start, at(0))
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package transcript
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/history"
+)
+
+// CSS colors for the game color codes ^0 to ^9, as rendered on a dark
+// background. ^7 is the default text color
+var Colors = [10]string{
+	"#6b6b6b", "#ff3131", "#86c000", "#ffad22", "#0082ba",
+	"#25bdf1", "#9750dd", "#ffffff", "#c0c0c0", "#8a8a8a",
+}
+
+type segment struct {
+	Color string
+	Text  string
+}
+
+// Split a string into runs of text sharing a color code
+func segments(s string) []segment {
+	var segs []segment
+	color := ""
+	var text strings.Builder
+
+	flush := func() {
+		if text.Len() > 0 {
+			segs = append(segs, segment{Color: color, Text: text.String()})
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '^' && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9' {
+			flush()
+			color = Colors[s[i+1]-'0']
+			i++
+			continue
+		}
+		text.WriteByte(s[i])
+	}
+	flush()
+	return segs
+}
+
+// Render a string with color codes as HTML spans
+func colorize(s string) template.HTML {
+	var b strings.Builder
+	for _, seg := range segments(s) {
+		if seg.Color == "" {
+			b.WriteString(html.EscapeString(seg.Text))
+			continue
+		}
+		fmt.Fprintf(&b, `<span style="color:%s">%s</span>`, seg.Color, html.EscapeString(seg.Text))
+	}
+	return template.HTML(b.String())
+}
+
+var page = template.Must(template.New("transcript").Funcs(template.FuncMap{
+	"colorize": colorize,
+	"stamp":    func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04:05") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { background: #1b1b1f; color: #e6e6e6; font: 14px/1.5 monospace; margin: 2em; }
+h1 { font-size: 1.3em; }
+table { border-collapse: collapse; }
+td { padding: 2px 10px; vertical-align: top; }
+td.time { color: #8a8a8a; white-space: nowrap; }
+td.sender { white-space: nowrap; text-align: right; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{len .Lines}} messages{{if .Lines}}, {{stamp (index .Lines 0).Time}} to {{stamp .Last.Time}} UTC{{end}}. Exported {{stamp .Exported}} UTC.</p>
+<table>
+{{range .Lines}}<tr><td class="time">{{stamp .Time}}</td><td class="sender">{{colorize .Sender}}</td><td>{{colorize .Message}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// Write a stand-alone HTML transcript of chat lines with colors preserved
+func WriteHTML(w io.Writer, title string, lines []history.ChatLine) error {
+	data := struct {
+		Title    string
+		Lines    []history.ChatLine
+		Last     history.ChatLine
+		Exported time.Time
+	}{
+		Title:    title,
+		Lines:    lines,
+		Exported: time.Now(),
+	}
+	if len(lines) > 0 {
+		data.Last = lines[len(lines)-1]
+	}
+
+	return page.Execute(w, data)
+}
+
+// Write a Markdown transcript of chat lines. Colors are kept as inline HTML
+// spans, which most Markdown renderers pass through
+func WriteMarkdown(w io.Writer, title string, lines []history.ChatLine) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", escapeMarkdown(title))
+	fmt.Fprintf(&b, "%d messages, exported %s UTC.\n\n", len(lines), time.Now().UTC().Format("2006-01-02 15:04:05"))
+	b.WriteString("| Time (UTC) | Player | Message |\n|---|---|---|\n")
+
+	for _, l := range lines {
+		fmt.Fprintf(&b, "| %s | %s | %s |\n",
+			l.Time.UTC().Format("2006-01-02 15:04:05"),
+			markdownCell(l.Sender),
+			markdownCell(l.Message),
+		)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func markdownCell(s string) string {
+	var b strings.Builder
+	for _, seg := range segments(s) {
+		text := escapeMarkdown(seg.Text)
+		if seg.Color == "" {
+			b.WriteString(text)
+			continue
+		}
+		fmt.Fprintf(&b, `<span style="color:%s">%s</span>`, seg.Color, text)
+	}
+	return b.String()
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`",
+	"[", `\[`, "]", `\]`, "<", "&lt;", ">", "&gt;", "&", "&amp;", "#", `\#`,
+	"\n", " ",
+)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
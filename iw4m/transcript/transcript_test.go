@@ -0,0 +1,119 @@
+package transcript
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yallamaztar/iw4m-go/iw4m/history"
+)
+
+func TestSegments(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []segment
+	}{
+		{"", nil},
+		{"plain", []segment{{"", "plain"}}},
+		{"^1red", []segment{{Colors[1], "red"}}},
+		{"a^2b^7c", []segment{{"", "a"}, {Colors[2], "b"}, {Colors[7], "c"}}},
+		{"^1^2two", []segment{{Colors[2], "two"}}},
+		{"^^3x", []segment{{"", "^"}, {Colors[3], "x"}}},
+		{"5^", []segment{{"", "5^"}}},
+		{"^a not a code", []segment{{"", "^a not a code"}}},
+	}
+
+	for _, tt := range tests {
+		if got := segments(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("segments(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestColorize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{"^1red", `<span style="color:#ff3131">red</span>`},
+		{"<b>&", "&lt;b&gt;&amp;"},
+		{`^1<script>alert("x")</script>`, `<span style="color:#ff3131">&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;</span>`},
+	}
+
+	for _, tt := range tests {
+		if got := string(colorize(tt.in)); got != tt.want {
+			t.Errorf("colorize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+var lines = []history.ChatLine{
+	{Time: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), Sender: "^1<img src=x onerror=alert(1)>", Message: "hi | *all* [x](y)"},
+	{Time: time.Date(2024, 3, 1, 12, 0, 5, 0, time.UTC), Sender: "bob", Message: "^2gg\nnext"},
+}
+
+func TestWriteHTML(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteHTML(&b, "<Match> & co", lines); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		"<title>&lt;Match&gt; &amp; co</title>",
+		"2 messages, 2024-03-01 12:00:00 to 2024-03-01 12:00:05 UTC",
+		`<span style="color:#ff3131">&lt;img src=x onerror=alert(1)&gt;</span>`,
+		`<span style="color:#86c000">gg`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("HTML transcript is missing %q", want)
+		}
+	}
+	if strings.Contains(out, "<img") {
+		t.Error("HTML transcript contains an unescaped tag")
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteMarkdown(&b, "# Match_1", lines); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		`# \# Match\_1`,
+		"2 messages, exported",
+		`| 2024-03-01 12:00:00 | <span style="color:#ff3131">&lt;img src=x onerror=alert(1)&gt;</span> | hi \| \*all\* \[x\](y) |`,
+		`| 2024-03-01 12:00:05 | bob | <span style="color:#86c000">gg next</span> |`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Markdown transcript is missing %q in\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "<img") {
+		t.Error("Markdown transcript contains an unescaped tag")
+	}
+}
+
+func TestEscapeMarkdown(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain text", "plain text"},
+		{"a|b", `a\|b`},
+		{"**bold** _it_ `code`", "\\*\\*bold\\*\\* \\_it\\_ \\`code\\`"},
+		{`back\slash`, `back\\slash`},
+		{"<script>", "&lt;script&gt;"},
+		{"&lt;", "&amp;lt;"},
+		{"two\nlines", "two lines"},
+	}
+
+	for _, tt := range tests {
+		if got := escapeMarkdown(tt.in); got != tt.want {
+			t.Errorf("escapeMarkdown(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
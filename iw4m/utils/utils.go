@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/Yallamaztar/iw4m-go/iw4m"
@@ -8,63 +10,116 @@ import (
 )
 
 type Utils struct {
-	iw4m *iw4m.IW4MWrapper
+	server server.Reader
 }
 
 // Create a new Utils wrapper
 func NewUtils(iw4m *iw4m.IW4MWrapper) *Utils {
-	return &Utils{iw4m: iw4m}
+	return &Utils{server: server.NewServer(iw4m)}
 }
 
+// Create a new Utils wrapper on top of any server backend
+func NewUtilsFromServer(s server.Reader) *Utils {
+	return &Utils{server: s}
+}
+
+// Report whether the webfront defines the given role
+func (u *Utils) HasRole(role string) (bool, error) {
+	_, err := u.RoleIndex(role)
+	if errors.Is(err, server.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Deprecated: use HasRole, which reports errors instead of returning false
 func (u *Utils) RoleExists(role string) bool {
-	roles, _ := server.NewServer(u.iw4m).Roles()
-	for _, r := range roles {
+	exists, _ := u.HasRole(role)
+	return exists
+}
+
+// Find the position of a role, lowest first, returning ErrNotFound if the
+// webfront does not define it
+func (u *Utils) RoleIndex(role string) (int, error) {
+	roles, err := u.server.Roles()
+	if err != nil {
+		return -1, err
+	}
+
+	for i, r := range roles {
 		if strings.EqualFold(r, role) {
-			return true
+			return i, nil
 		}
 	}
-	return false
+
+	return -1, fmt.Errorf("role %q: %w", role, server.ErrNotFound)
 }
 
+// Deprecated: use RoleIndex, which reports errors instead of returning -1
 func (u *Utils) RolePosition(role string) int {
-	roles, _ := server.NewServer(u.iw4m).Roles()
+	pos, _ := u.RoleIndex(role)
+	return pos
+}
 
-	for i, r := range roles {
-		if strings.EqualFold(r, role) {
-			return i
-		}
+// Compare two roles, returning a positive number if a outranks b, a negative
+// number if b outranks a and 0 if they are equal. Creator outranks every role
+func (u *Utils) CompareRoles(a, b string) (int, error) {
+	aCreator := strings.EqualFold(a, "creator")
+	bCreator := strings.EqualFold(b, "creator")
+	switch {
+	case aCreator && bCreator:
+		return 0, nil
+	case aCreator:
+		return 1, nil
+	case bCreator:
+		return -1, nil
 	}
 
-	return -1
+	aPos, err := u.RoleIndex(a)
+	if err != nil {
+		return 0, err
+	}
+	bPos, err := u.RoleIndex(b)
+	if err != nil {
+		return 0, err
+	}
+
+	return aPos - bPos, nil
 }
 
+// Deprecated: use CompareRoles, which reports errors instead of returning false
 func (u *Utils) IsHigherRole(roleToCheck, role string) bool {
 	if strings.ToLower(roleToCheck) == "creator" {
 		return true
 	}
 
-	rolePos := u.RolePosition(role)
-	checkPos := u.RolePosition(roleToCheck)
-
-	if rolePos == -1 || checkPos == -1 {
-		return false
-	}
-
-	return rolePos < checkPos
+	cmp, err := u.CompareRoles(roleToCheck, role)
+	return err == nil && cmp > 0
 }
 
+// Deprecated: use CompareRoles, which reports errors instead of returning true
 func (u *Utils) IsLowerRole(roleToCheck, role string) bool {
 	return !u.IsHigherRole(role, roleToCheck)
 }
 
-func (u *Utils) IsPlayerOnline(player string) bool {
-	players, _ := server.NewServer(u.iw4m).ListPlayers()
+// Report whether a player with the given name is online
+func (u *Utils) PlayerOnline(player string) (bool, error) {
+	players, err := u.server.ListPlayers()
+	if err != nil {
+		return false, err
+	}
 
 	for _, p := range players {
 		if p.Name == player {
-			return true
+			return true, nil
 		}
 	}
 
-	return false
+	return false, nil
+}
+
+// Deprecated: use PlayerOnline, which reports errors instead of returning false
+func (u *Utils) IsPlayerOnline(player string) bool {
+	online, _ := u.PlayerOnline(player)
+	return online
 }